@@ -0,0 +1,141 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/pflag"
+
+	"toolman.org/base/log/v2"
+)
+
+// Config keys may be renamed while still honoring their previous names by
+// adding one or more "alias" options to a field's `cfg` tag, e.g.:
+//
+//     Addr string `cfg:"listen-addr,alias=addr,alias=bind"`
+//
+// Aliases are recognized in config files, environment variables and flags.
+// Each use of an alias is logged (and recorded in the Config's LoadReport)
+// and it is an error for both an alias and its canonical key to be given
+// different values by the same source.
+
+// addAliases records the aliases for each of fd's fields and, for those
+// fields having a flag, adds a hidden, deprecated flag for each alias.
+func (c *Config) addAliases(fd *featureDefn, fs *pflag.FlagSet) {
+	for _, fi := range fd.fields {
+		key := fd.label.Key(fi.key)
+
+		for _, a := range fi.aliases {
+			c.alias[fd.label.Key(a)] = key
+		}
+
+		f := fs.Lookup(fi.key)
+		if f == nil {
+			continue
+		}
+
+		for _, a := range fi.aliases {
+			if fs.Lookup(a) != nil {
+				continue
+			}
+
+			fs.AddFlag(&pflag.Flag{
+				Name:       a,
+				Usage:      f.Usage,
+				Value:      f.Value,
+				DefValue:   f.DefValue,
+				Hidden:     true,
+				Deprecated: fmt.Sprintf("use --%s instead", key),
+			})
+		}
+	}
+}
+
+func (c *Config) aliasNames() []string {
+	var list []string
+	for a := range c.alias {
+		list = append(list, a)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+// resolveAliases moves the values for any aliases found in raw (which was
+// read from source) to their canonical keys.
+func (c *Config) resolveAliases(raw map[string]interface{}, source string) error {
+	for _, a := range c.aliasNames() {
+		av, ok := lookupKey(raw, a)
+		if !ok {
+			continue
+		}
+
+		key := c.alias[a]
+
+		if kv, ok := lookupKey(raw, key); ok && !sameValue(kv, av) {
+			return aliasConflictError(key, a, source)
+		}
+
+		deleteKey(raw, a)
+		setKey(raw, key, av)
+		c.useAlias(a, key, source)
+	}
+
+	return nil
+}
+
+// bindEnvAliases binds each canonical key to its alias' environment variable
+// if only the latter is set.
+func (c *Config) bindEnvAliases() error {
+	for _, a := range c.aliasNames() {
+		ae := c.envName(a)
+
+		av, ok := lookupEnv(ae)
+		if !ok {
+			continue
+		}
+
+		key := c.alias[a]
+
+		if kv, ok := lookupEnv(c.envName(key)); ok {
+			if kv != av {
+				return aliasConflictError(key, a, originEnv)
+			}
+			continue
+		}
+
+		if err := c.BindEnv(key, ae); err != nil {
+			return err
+		}
+
+		c.useAlias(a, key, originEnv)
+	}
+
+	return nil
+}
+
+func (c *Config) useAlias(alias, key, source string) {
+	log.Warningf("Config key %q is deprecated; use %q instead (from %s)", alias, key, source)
+	c.report.Aliases = append(c.report.Aliases, &AliasUse{alias, key, source})
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+	"github.com/spf13/pflag"
+)
+
+type aliasFeature struct {
+	Addr string `cfg:"listen-addr,alias=addr,alias=bind"`
+	Name string `cfg:"name"`
+}
+
+func (af *aliasFeature) FlagSet(fs *pflag.FlagSet) {
+	fs.StringVar(&af.Addr, "listen-addr", af.Addr, "Listen address")
+}
+
+func (af *aliasFeature) Validate(context.Context) error { return nil }
+
+type aliasTestcase struct {
+	text string
+	env  map[string]string
+	args []string
+	want string
+	uses []*AliasUse
+	err  error
+}
+
+func TestAliases(t *testing.T) {
+	tests := map[string]*aliasTestcase{
+		"file": &aliasTestcase{
+			text: `{ "net": { "addr": ":80" } }`,
+			want: ":80",
+			uses: []*AliasUse{{"net.addr", "net.listen-addr", originFile}},
+		},
+		"file-same": &aliasTestcase{
+			text: `{ "net": { "addr": ":80", "listen-addr": ":80" } }`,
+			want: ":80",
+			uses: []*AliasUse{{"net.addr", "net.listen-addr", originFile}},
+		},
+		"file-conflict": &aliasTestcase{
+			text: `{ "net": { "addr": ":80", "listen-addr": ":81" } }`,
+			err:  aliasConflictError("net.listen-addr", "net.addr", originFile),
+		},
+		"env": &aliasTestcase{
+			text: `{ "net": { "listen-addr": ":80" } }`,
+			env:  map[string]string{"ALIASTEST_NET_BIND": ":82"},
+			want: ":82",
+			uses: []*AliasUse{{"net.bind", "net.listen-addr", originEnv}},
+		},
+		"flag": &aliasTestcase{
+			text: `{}`,
+			args: []string{"--net.bind", ":83"},
+			want: ":83",
+			uses: []*AliasUse{{"net.bind", "net.listen-addr", originFlag}},
+		},
+		"flag-conflict": &aliasTestcase{
+			text: `{}`,
+			args: []string{"--net.bind", ":83", "--net.listen-addr", ":84"},
+			err:  aliasConflictError("net.listen-addr", "net.bind", originFlag),
+		},
+		"set": &aliasTestcase{
+			text: `{ "net": { "listen-addr": ":80" } }`,
+			args: []string{"--set", "net.addr=:85"},
+			want: ":85",
+			uses: []*AliasUse{{"net.addr", "net.listen-addr", originFlag}},
+		},
+		"set-conflict": &aliasTestcase{
+			text: `{}`,
+			args: []string{"--set", "net.addr=:85", "--set", "net.listen-addr=:86"},
+			err:  setError("net.listen-addr=:86", "net.listen-addr", aliasConflictError("net.listen-addr", "net.addr", originFlag)),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, tc.test)
+	}
+}
+
+func (tc *aliasTestcase) test(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("net", func() Feature { return new(aliasFeature) })
+
+	for k, v := range tc.env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	c := New("aliastest", FromReader("json", bytes.NewBufferString(tc.text)))
+
+	if err := c.flags.Parse(tc.args); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Load(context.Background()); !reflect.DeepEqual(err, tc.err) {
+		t.Fatalf("c.Load() == (%v); Wanted (%v)", err, tc.err)
+	}
+
+	if tc.err != nil {
+		return
+	}
+
+	if got := c.Feature("net").(*aliasFeature).Addr; got != tc.want {
+		t.Errorf("Addr == %q; Wanted %q", got, tc.want)
+	}
+
+	if got := c.Report().Aliases; !reflect.DeepEqual(got, tc.uses) {
+		t.Errorf("c.Report().Aliases == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(tc.uses))
+	}
+}
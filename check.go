@@ -0,0 +1,250 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// checkFlag is the name of the flag that causes Load to check the config,
+// print a report and exit.
+const checkFlag = "config-check"
+
+// Feature status values for a FeatureCheck.
+const (
+	CheckOK         = "ok"
+	CheckFailed     = "error"
+	CheckDisabled   = "disabled"
+	CheckUnselected = "unselected"
+	CheckSkipped    = "skipped"
+)
+
+// CheckReport is the result of a pre-flight config check (see Check).
+type CheckReport struct {
+	OK       bool             `json:"ok"`
+	Sources  []string         `json:"sources"`
+	OneOfs   map[string]Label `json:"oneofs,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+	Errors   []string         `json:"errors,omitempty"`
+	Features []*FeatureCheck  `json:"features"`
+}
+
+// FeatureCheck is the check result for a single Feature. Status is one of
+// CheckOK, CheckFailed, CheckDisabled, CheckUnselected or CheckSkipped (for
+// Features not checked due to an earlier, general error).
+type FeatureCheck struct {
+	Label  Label  `json:"label"`
+	OneOf  string `json:"oneof,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Check loads all configuration and validates every Feature -- without
+// starting any of them -- and returns a report of the results. Unlike Load,
+// Check does not stop at the first validation error. Checks are performed
+// using a separate set of Features; the Config itself (whether or not it has
+// been loaded) and its running Features are left untouched.
+func (c *Config) Check(ctx context.Context) *CheckReport {
+	c.mu.RLock()
+	n := c.fork()
+	c.mu.RUnlock()
+
+	return n.checkAll(ctx)
+}
+
+func (c *Config) checkAll(ctx context.Context) *CheckReport {
+	cr := &CheckReport{OneOfs: make(map[string]Label)}
+
+	failf := func(err error) {
+		cr.Errors = append(cr.Errors, err.Error())
+	}
+
+	var all map[string]interface{}
+
+	err := c.nwerr
+	if err == nil {
+		all, err = c.loadSettings(ctx)
+	}
+
+	if err != nil {
+		failf(err)
+	} else {
+		for _, name := range c.oneOfNames() {
+			if err := c.selectOneOf(name, all); err != nil {
+				failf(err)
+			}
+		}
+	}
+
+	cr.Sources = append([]string{}, c.report.Sources...)
+
+	for name, l := range c.oomap {
+		cr.OneOfs[name] = l
+	}
+
+	for _, au := range c.report.Aliases {
+		cr.Warnings = append(cr.Warnings, fmt.Sprintf("deprecated key %q used in place of %q (from %s)", au.Alias, au.Key, au.Source))
+	}
+
+	if c.opts.keys != StrictKeys && c.raw != nil {
+		for _, uk := range c.unknownKeys() {
+			cr.Warnings = append(cr.Warnings, "unknown key "+uk.String())
+		}
+	}
+
+	for _, fd := range c.defs {
+		fc := &FeatureCheck{Label: fd.label, OneOf: fd.oneof}
+		cr.Features = append(cr.Features, fc)
+
+		if err != nil {
+			fc.Status = CheckSkipped
+			continue
+		}
+
+		switch fc.Status = c.inactive(fd); fc.Status {
+		case CheckUnselected:
+			if fd.configured {
+				cr.Warnings = append(cr.Warnings, fmt.Sprintf("feature %q is configured but not selected for %q", fd.label, fd.oneof))
+			}
+			continue
+		case CheckDisabled:
+			continue
+		}
+
+		if ferr := c.populateFeature(ctx, fd); ferr != nil {
+			fc.Status = CheckFailed
+			fc.Error = ferr.Error()
+		} else {
+			fc.Status = CheckOK
+		}
+	}
+
+	cr.OK = len(cr.Errors) == 0
+	for _, fc := range cr.Features {
+		if fc.Status == CheckFailed {
+			cr.OK = false
+		}
+	}
+
+	return cr
+}
+
+// configCheck is called by Load when the --config-check flag is given. It
+// writes a check report in the requested format to stdout and exits the
+// program with a status of 0 if the check passed or 1 if it did not.
+func (c *Config) configCheck(ctx context.Context) {
+	cr := c.Check(ctx)
+
+	var err error
+	switch c.check {
+	case "json":
+		err = cr.WriteJSON(os.Stdout)
+	case "text":
+		err = cr.WriteText(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "--%s: unknown report format %q (want text or json)\n", checkFlag, c.check)
+		exit(2)
+		return
+	}
+
+	if err != nil || !cr.OK {
+		exit(1)
+		return
+	}
+
+	exit(0)
+}
+
+// WriteJSON writes this report to w as indented JSON.
+func (cr *CheckReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cr)
+}
+
+// WriteText writes this report to w in a human readable form.
+func (cr *CheckReport) WriteText(w io.Writer) error {
+	status := "OK"
+	if !cr.OK {
+		status = "FAILED"
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Config check: %s\n", status)
+
+	if len(cr.Sources) > 0 {
+		fmt.Fprintln(tw, "\nSources:")
+		for _, s := range cr.Sources {
+			fmt.Fprintf(tw, "  %s\n", s)
+		}
+	}
+
+	if len(cr.OneOfs) > 0 {
+		fmt.Fprintln(tw, "\nOneOf selections:")
+		for _, name := range sortedKeys(cr.OneOfs) {
+			fmt.Fprintf(tw, "  %s:\t%s\n", name, cr.OneOfs[name])
+		}
+	}
+
+	for _, sect := range []struct {
+		title string
+		list  []string
+	}{{"Warnings", cr.Warnings}, {"Errors", cr.Errors}} {
+		if len(sect.list) > 0 {
+			fmt.Fprintf(tw, "\n%s:\n", sect.title)
+			for _, s := range sect.list {
+				fmt.Fprintf(tw, "  %s\n", s)
+			}
+		}
+	}
+
+	fmt.Fprintln(tw, "\nFeatures:")
+	for _, fc := range cr.Features {
+		label := string(fc.Label)
+		if label == "" {
+			label = "(base)"
+		}
+
+		line := fmt.Sprintf("  %s\t%s", label, fc.Status)
+		if fc.Error != "" {
+			line += ": " + fc.Error
+		}
+		fmt.Fprintln(tw, line)
+	}
+
+	return tw.Flush()
+}
+
+func sortedKeys(m map[string]Label) []string {
+	var list []string
+	for k := range m {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
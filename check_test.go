@@ -0,0 +1,223 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kr/pretty"
+	"github.com/spf13/pflag"
+)
+
+type failFeature struct {
+	Level int `cfg:"level"`
+}
+
+func (ff *failFeature) FlagSet(*pflag.FlagSet) {}
+
+func (ff *failFeature) Validate(context.Context) error {
+	if ff.Level > 3 {
+		return errors.New("level too high")
+	}
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+	Register("fail", func() Feature { return new(failFeature) })
+	Register("proc", func() Feature { return &enumFeature{Mode: "fast"} })
+	RegisterOneOf("store", "disk", func() Feature { return mkTestFeature() })
+	RegisterOneOf("store", "mem", func() Feature { return mkTestFeature() })
+
+	text := `{
+		"fail":  { "level": 5 },
+		"proc":  { "mode": "medium" },
+		"store": { "type": "mem" },
+		"disk":  { "thing-one": "x" },
+		"bogus": 1
+	}`
+
+	c := New("checktest", WarnOnUnknownKeys, FromReader("json", strings.NewReader(text)))
+
+	got := c.Check(context.Background())
+
+	want := &CheckReport{
+		OK:      false,
+		Sources: []string{"reader:json"},
+		OneOfs:  map[string]Label{"store": "mem"},
+		Warnings: []string{
+			`unknown key "bogus" from source`,
+			`feature "disk" is configured but not selected for "store"`,
+		},
+		Features: []*FeatureCheck{
+			{Label: "disk", OneOf: "store", Status: CheckUnselected},
+			{Label: "fail", Status: CheckFailed, Error: "level too high"},
+			{Label: "feat", Status: CheckOK},
+			{Label: "mem", OneOf: "store", Status: CheckOK},
+			{Label: "proc", Status: CheckFailed, Error: `invalid value "medium" for "proc.mode" (valid choices: fast, slow)`},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("c.Check() == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	if c.loaded {
+		t.Errorf("c.Check() left Config loaded")
+	}
+
+	var buf bytes.Buffer
+	if err := got.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"Config check: FAILED", "  store:  mem", "  fail  error: level too high"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("WriteText(...) missing %q in:\n%s", s, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := got.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var rt *CheckReport
+	if err := json.Unmarshal(buf.Bytes(), &rt); err != nil || !reflect.DeepEqual(rt, want) {
+		t.Errorf("WriteJSON(...) round trip == (%# v, %v); Wanted %# v", pretty.Formatter(rt), err, pretty.Formatter(want))
+	}
+
+	c = New("checktest", FromReader("json", strings.NewReader(`{ "bad": `)))
+	if got := c.Check(context.Background()); got.OK || len(got.Errors) != 1 || got.Features[0].Status != CheckSkipped {
+		t.Errorf("c.Check() == %# v; Wanted general error", pretty.Formatter(got))
+	}
+}
+
+func TestCheckAgreesWithLoad(t *testing.T) {
+	tests := []struct {
+		text string
+		ok   bool
+	}{
+		{`{ "otf": { "type": "feata" }, "featb": { "option": { "bad": 1 } } }`, true},
+		{`{ "opt": { "enabled": false, "addr": { "bad": 1 } } }`, true},
+		{`{ "opt": { "enabled": true } }`, false},
+		{`{ "fail": { "level": "abc" } }`, false},
+		{`{ "fail": { "level": 5 } }`, false},
+	}
+
+	for _, tc := range tests {
+		reset := useTestRegistry()
+
+		RegisterOneOf("otf", "feata", func() Feature { return new(oneofFeatureA) })
+		RegisterOneOf("otf", "featb", func() Feature { return new(oneofFeatureB) })
+		Register("opt", func() Feature { return new(optionalFeature) })
+		Register("fail", func() Feature { return new(failFeature) })
+
+		ctx := context.Background()
+
+		c := New("checktest", FromReader("json", strings.NewReader(tc.text)))
+
+		if got := c.Check(ctx); got.OK != tc.ok {
+			t.Errorf("[%s] c.Check().OK == %t; Wanted %t: %# v", tc.text, got.OK, tc.ok, pretty.Formatter(got))
+		}
+
+		if err := c.Load(ctx); (err == nil) != tc.ok {
+			t.Errorf("[%s] c.Load() == %v; Wanted ok=%t", tc.text, err, tc.ok)
+		}
+
+		reset()
+	}
+}
+
+func TestCheckLoaded(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("rl", func() Feature { return new(reloadFeature) })
+
+	c := New("checktest", FromReader("json", strings.NewReader(`{ "rl": { "level": 2 } }`)))
+
+	ctx := context.Background()
+
+	if err := c.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := c.Feature("rl").(*reloadFeature)
+
+	if got := c.Check(ctx); !got.OK {
+		t.Errorf("c.Check() == %# v; Wanted OK", pretty.Formatter(got))
+	}
+
+	if got := c.Feature("rl").(*reloadFeature); got != orig || got.closed || got.Level != 2 {
+		t.Errorf("after Check: c.Feature(%q) == %#v; Wanted original, open Feature with Level 2", "rl", got)
+	}
+
+	if !c.loaded || c.GetInt("rl.level") != 2 || len(c.active) != 1 {
+		t.Errorf("after Check: loaded == %t, rl.level == %d, active == %d; Wanted true, 2, 1", c.loaded, c.GetInt("rl.level"), len(c.active))
+	}
+
+	if err := c.Close(ctx); err != nil || !orig.closed {
+		t.Errorf("c.Close() == %v (closed: %t); Wanted nil (closed: true)", err, orig.closed)
+	}
+}
+
+func TestConfigCheckFlag(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	defer func(f func(int)) { exit = f }(exit)
+
+	var code int
+	exit = func(c int) { code = c }
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	c := New("checktest", FromReader("json", strings.NewReader(`{}`)))
+	if err := c.flags.Parse([]string{"--config-check=yaml"}); err != nil {
+		t.Fatal(err)
+	}
+
+	code = -1
+	c.configCheck(context.Background())
+
+	if code != 2 {
+		t.Errorf("--config-check=yaml: exit(%d); Wanted exit(2)", code)
+	}
+
+	c = New("checktest")
+	if err := c.flags.Parse([]string{"--config-check"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.check != "text" {
+		t.Errorf("--config-check: c.check == %q; Wanted %q", c.check, "text")
+	}
+}
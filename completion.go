@@ -0,0 +1,285 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Argument completion kinds for flag values.
+const (
+	complNone = iota // flag takes no argument
+	complAny         // any value; no specific completion
+	complFile        // file names
+	complDir         // directory names
+	complList        // one of a fixed list of values
+)
+
+type flagCompl struct {
+	name   string
+	short  string
+	usage  string
+	kind   int
+	values []string
+	repeat bool
+}
+
+// Completion writes a shell completion script for this program's flags to
+// w. The shell may be "bash", "zsh" or "fish". Beyond flag names, the script
+// completes file names for --config-file and --env-file, directories for
+// --config-path, member labels for OneOf selector flags and the allowed
+// values for flags whose config key has an "enum" constraint.
+func (c *Config) Completion(shell string, w io.Writer) error {
+	prog := filepath.Base(os.Args[0])
+
+	c.mu.RLock()
+	fcs := c.flagCompletions()
+	c.mu.RUnlock()
+
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion(prog, fcs)
+	case "zsh":
+		script = zshCompletion(prog, fcs)
+	case "fish":
+		script = fishCompletion(prog, fcs)
+	default:
+		return fmt.Errorf("unsupported shell for completion: %q", shell)
+	}
+
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+// flagCompletions returns completion details for each non-hidden flag.
+func (c *Config) flagCompletions() []*flagCompl {
+	var list []*flagCompl
+
+	c.flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+
+		_, usage := pflag.UnquoteUsage(f)
+
+		fc := &flagCompl{name: f.Name, short: f.Shorthand, usage: usage, kind: complAny}
+
+		typ := f.Value.Type()
+		fc.repeat = strings.HasSuffix(typ, "Slice") || strings.HasSuffix(typ, "Array")
+
+		switch {
+		case typ == "bool":
+			fc.kind = complNone
+		case f.Name == "config-file" || f.Name == "env-file":
+			fc.kind = complFile
+		case f.Name == "config-path":
+			fc.kind = complDir
+		case f.Name == helpFeatureFlag:
+			fc.kind = complList
+			for _, l := range c.features() {
+				fc.values = append(fc.values, string(l))
+			}
+		default:
+			if vals := c.flagChoices(f); len(vals) > 0 {
+				fc.kind = complList
+				fc.values = vals
+			}
+		}
+
+		list = append(list, fc)
+	})
+
+	return list
+}
+
+// flagChoices returns the allowed values for flag f -- i.e. the member
+// labels for a OneOf selector flag or the values of an "enum" constraint.
+func (c *Config) flagChoices(f *pflag.Flag) []string {
+	var list []string
+
+	if _, ok := c.sels[f.Name]; ok {
+		for _, l := range c.ooset[f.Name] {
+			list = append(list, string(l))
+		}
+		return list
+	}
+
+	key, ok := c.flagKey(f)
+	if !ok {
+		return nil
+	}
+
+	for _, fd := range c.defs {
+		for _, fi := range fd.fields {
+			if strings.ToLower(fd.label.Key(fi.key)) == key {
+				return fi.enum
+			}
+		}
+	}
+
+	return nil
+}
+
+var nonIdent = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+//--------------------------------------
+
+func bashCompletion(prog string, fcs []*flagCompl) string {
+	fn := "_" + nonIdent.ReplaceAllString(prog, "_") + "_completion"
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# bash completion for %s\n\n", prog)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur prev\n")
+	b.WriteString("    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n\n")
+	b.WriteString("    # Handle --flag=value (bash splits words on '=')\n")
+	b.WriteString("    if [[ \"$cur\" == \"=\" ]]; then\n")
+	b.WriteString("        cur=\"\"\n")
+	b.WriteString("    elif [[ \"$prev\" == \"=\" ]]; then\n")
+	b.WriteString("        prev=\"${COMP_WORDS[COMP_CWORD-2]}\"\n")
+	b.WriteString("    fi\n\n")
+	b.WriteString("    case \"$prev\" in\n")
+
+	var names []string
+	for _, fc := range fcs {
+		flags := "--" + fc.name
+		if fc.short != "" {
+			flags += "|-" + fc.short
+		}
+
+		switch fc.kind {
+		case complFile:
+			fmt.Fprintf(&b, "        %s)\n            COMPREPLY=( $(compgen -f -- \"$cur\") )\n            return ;;\n", flags)
+		case complDir:
+			fmt.Fprintf(&b, "        %s)\n            COMPREPLY=( $(compgen -d -- \"$cur\") )\n            return ;;\n", flags)
+		case complList:
+			fmt.Fprintf(&b, "        %s)\n            COMPREPLY=( $(compgen -W %q -- \"$cur\") )\n            return ;;\n", flags, strings.Join(fc.values, " "))
+		case complAny:
+			fmt.Fprintf(&b, "        %s)\n            return ;;\n", flags)
+		}
+
+		names = append(names, "--"+fc.name)
+	}
+
+	b.WriteString("    esac\n\n")
+	fmt.Fprintf(&b, "    COMPREPLY=( $(compgen -W %q -- \"$cur\") )\n", strings.Join(names, " "))
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "complete -o default -F %s %s\n", fn, prog)
+
+	return b.String()
+}
+
+//--------------------------------------
+
+var zshEscaper = strings.NewReplacer(`\`, `\\`, `'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`)
+
+func zshCompletion(prog string, fcs []*flagCompl) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "#compdef %s\n\n", prog)
+	b.WriteString("_arguments -s \\\n")
+
+	for i, fc := range fcs {
+		var eq, action string
+		switch fc.kind {
+		case complNone:
+		case complFile:
+			eq, action = "=", ":file:_files"
+		case complDir:
+			eq, action = "=", ":directory:_files -/"
+		case complList:
+			eq, action = "=", fmt.Sprintf(":value:(%s)", zshEscaper.Replace(strings.Join(fc.values, " ")))
+		default:
+			eq, action = "=", ":value: "
+		}
+
+		rest := fmt.Sprintf("[%s]%s", zshEscaper.Replace(fc.usage), action)
+
+		var spec string
+		switch {
+		case fc.short == "" && fc.repeat:
+			spec = fmt.Sprintf("'*--%s%s%s'", fc.name, eq, rest)
+		case fc.short == "":
+			spec = fmt.Sprintf("'--%s%s%s'", fc.name, eq, rest)
+		case fc.repeat:
+			spec = fmt.Sprintf("'*'{-%s,--%s%s}'%s'", fc.short, fc.name, eq, rest)
+		default:
+			spec = fmt.Sprintf("'(-%s --%s)'{-%s,--%s%s}'%s'", fc.short, fc.name, fc.short, fc.name, eq, rest)
+		}
+
+		b.WriteString("  " + spec)
+		if i < len(fcs)-1 {
+			b.WriteString(" \\")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+//--------------------------------------
+
+func fishCompletion(prog string, fcs []*flagCompl) string {
+	quote := func(s string) string {
+		return "'" + strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# fish completion for %s\n\n", prog)
+
+	for _, fc := range fcs {
+		fmt.Fprintf(&b, "complete -c %s -l %s", prog, fc.name)
+
+		if fc.short != "" {
+			fmt.Fprintf(&b, " -s %s", fc.short)
+		}
+
+		switch fc.kind {
+		case complFile:
+			b.WriteString(" -r -F")
+		case complDir:
+			b.WriteString(" -x -a '(__fish_complete_directories)'")
+		case complList:
+			fmt.Fprintf(&b, " -x -a %s", quote(strings.Join(fc.values, " ")))
+		case complAny:
+			b.WriteString(" -x")
+		}
+
+		if fc.usage != "" {
+			fmt.Fprintf(&b, " -d %s", quote(fc.usage))
+		}
+
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("proc", func() Feature { return &enumFeature{Mode: "fast"} })
+	RegisterOneOf("store", "disk", func() Feature { return mkTestFeature() })
+	RegisterOneOf("store", "mem", func() Feature { return mkTestFeature() })
+
+	c := New("compltest")
+
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"bash", []string{
+			"--config-file)\n            COMPREPLY=( $(compgen -f -- \"$cur\") )",
+			"--config-path)\n            COMPREPLY=( $(compgen -d -- \"$cur\") )",
+			"--store)\n            COMPREPLY=( $(compgen -W \"disk mem\" -- \"$cur\") )",
+			"--proc.mode)\n            COMPREPLY=( $(compgen -W \"fast slow\" -- \"$cur\") )",
+			"--disk.thing-one",
+			"complete -o default -F _",
+		}},
+		{"zsh", []string{
+			"#compdef ",
+			`'--config-file=[If specified, use only this specific config file (i.e. don'\''t search config path)]:file:_files'`,
+			`'*--config-path=[Comma separated list of directories to search for config (may be specified more than once)]:directory:_files -/'`,
+			`'--store=[Select the active "store" feature (one of\: disk, mem)]:value:(disk mem)'`,
+			`'--proc.mode=[Processing mode]:value:(fast slow)'`,
+		}},
+		{"fish", []string{
+			"-l config-file -r -F -d ",
+			"-l config-path -x -a '(__fish_complete_directories)'",
+			"-l store -x -a 'disk mem'",
+			"-l proc.mode -x -a 'fast slow' -d 'Processing mode'",
+			"-l help-feature -x -a 'disk mem proc'",
+		}},
+	}
+
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := c.Completion(tc.shell, &buf); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range tc.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("c.Completion(%q, w) missing %q in:\n%s", tc.shell, want, buf.String())
+			}
+		}
+	}
+
+	if err := c.Completion("tcsh", new(bytes.Buffer)); err == nil {
+		t.Errorf("c.Completion(%q, w) == nil; Wanted error", "tcsh")
+	}
+}
@@ -26,7 +26,9 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/pflag"
@@ -37,13 +39,36 @@ import (
 )
 
 type Config struct {
-	file  string
-	path  []string
-	defs  []*featureDefn
-	fmap  map[Label]Feature
-	oomap map[string]Label
-	flags *pflag.FlagSet
-	opts  *cfgOptions
+	name      string
+	file      string
+	raw       map[string]interface{}
+	fdata     map[string]interface{}
+	dotenv    map[string]string
+	dotKeys   map[string]bool
+	bundle    map[string]interface{}
+	spath     []string
+	path      []string
+	xpath     []string
+	envFiles  []string
+	sets      []string
+	helpFeats []string
+	check     string
+	setKeys   map[string]bool
+	defs      []*featureDefn
+	fmap      map[Label]Feature
+	oomap     map[string]Label
+	ooset     map[string][]Label
+	sels      map[string]string
+	alias     map[string]string
+	active    []*featureDefn
+	flags     *pflag.FlagSet
+	opts      *cfgOptions
+	report    *LoadReport
+	loaded    bool
+	lderr     error
+	nwerr     error
+	mu        sync.RWMutex
+	rmu       sync.Mutex // serializes Load and Reload
 	*viper.Viper
 }
 
@@ -58,31 +83,46 @@ func New(name string, options ...Option) *Config {
 		o.setopt(opts)
 	}
 
-	v := viper.New()
-
-	v.SetConfigName(name)
-	v.SetEnvPrefix(opts.envPrefix)
-
 	c := &Config{
-		defs:  registry.reify(),
-		fmap:  make(map[Label]Feature),
-		oomap: make(map[string]Label),
-		flags: fs,
-		opts:  opts,
-		Viper: v,
+		name:   name,
+		defs:   registry.reify(),
+		fmap:   make(map[Label]Feature),
+		oomap:  make(map[string]Label),
+		ooset:  make(map[string][]Label),
+		sels:   make(map[string]string),
+		alias:  make(map[string]string),
+		flags:  fs,
+		opts:   opts,
+		report: new(LoadReport),
+		Viper:  newViper(name, opts.envPrefix),
 	}
 
 	fs.StringVar(&c.file, "config-file", "",
 		"If specified, use only this specific config file (i.e. don't search config path)")
 
-	fs.StringSliceVar(&c.path, "config-path", defaultCfgPath(),
+	fs.StringSliceVar(&c.path, "config-path", opts.configPath(name),
 		"Comma separated list of directories to search for config (may be specified more than once)")
 
+	fs.StringSliceVar(&c.envFiles, "env-file", nil,
+		"Comma separated list of environment files to load (may be specified more than once)")
+
+	fs.StringArrayVar(&c.sets, setFlag, nil,
+		"Override any config key as key=value (may be specified more than once)")
+
+	fs.StringSliceVar(&c.helpFeats, helpFeatureFlag, nil,
+		"Show usage for only the given (comma separated) features and exit")
+
+	fs.StringVar(&c.check, checkFlag, "",
+		"Load and validate config, print a report (as text or json) and exit")
+	fs.Lookup(checkFlag).NoOptDefVal = "text"
+
 	// If a base feature is provided, we prepend it to our list of features.
 	var bf *featureDefn
 	if opts.base != nil {
 		bf = &featureDefn{
 			Feature: opts.base,
+			migs:    registry.migrations(""),
+			initial: copyFeature(opts.base),
 		}
 
 		bf.extractDefaults()
@@ -90,6 +130,22 @@ func New(name string, options ...Option) *Config {
 		c.defs = append([]*featureDefn{bf}, c.defs...)
 	}
 
+	for _, fd := range c.defs {
+		if fd.oneof != "" {
+			c.ooset[fd.oneof] = append(c.ooset[fd.oneof], fd.label)
+		}
+	}
+
+	// Each "oneof" set gets a selector flag named for the set itself.
+	for _, name := range c.oneOfNames() {
+		if fs.Lookup(name) != nil {
+			c.newError(flagConflictError(name))
+			continue
+		}
+		fs.String(name, "", fmt.Sprintf("Select the active %q feature (one of: %s)", name, joinLabels(c.ooset[name])))
+		c.sels[name] = selectorKey(name)
+	}
+
 	for _, fd := range c.defs {
 		var pfx string
 		fsn := fsName
@@ -104,6 +160,9 @@ func New(name string, options ...Option) *Config {
 
 		fd.FlagSet(ffs)
 
+		c.addAliases(fd, ffs)
+		fd.addEnabled(ffs)
+
 		ffs.VisitAll(func(f *pflag.Flag) {
 			// Prepend the feature's label to the flag name
 			// (if it's not there already)
@@ -111,89 +170,336 @@ func New(name string, options ...Option) *Config {
 				f.Name = pfx + f.Name
 			}
 
+			if _, ok := c.sels[f.Name]; ok {
+				c.newError(flagConflictError(f.Name))
+			}
+
+			fd.flags = append(fd.flags, f)
+
 			// Set the flag's default value
-			f.DefValue = stringify(fd.defaults[f.Name])
+			key := f.Name
+			if ak, ok := c.alias[key]; ok {
+				key = ak
+			}
+			f.DefValue = stringify(fd.defaults[key])
 		})
 
 		fs.AddFlagSet(ffs)
 	}
 
+	fs.Usage = c.usage
+
 	return c
 }
 
+// newError records the first error encountered by New so it may be
+// returned by Load.
+func (c *Config) newError(err error) {
+	if c.nwerr == nil {
+		c.nwerr = err
+	}
+}
+
 func (c *Config) Flags() *toolman.InitOption {
 	return toolman.FlagSet(c.flags)
 }
 
+// AddConfigPath adds a directory to be searched for the config file. Paths
+// added here are searched (in the order added) before those provided by the
+// --config-path flag and, like them, are subject to environment variable and
+// "~" expansion (see SearchPath).
+//
+// Note that this method shadows the one provided by the embedded viper: the
+// Config does its own config file search and the underlying viper's search
+// path is never consulted. Calling c.Viper.AddConfigPath directly has no
+// effect.
+func (c *Config) AddConfigPath(in string) {
+	c.xpath = append(c.xpath, in)
+}
+
+func newViper(name, envPrefix string) *viper.Viper {
+	v := viper.New()
+
+	v.SetConfigName(name)
+	v.SetEnvPrefix(envPrefix)
+
+	return v
+}
+
+// Load reads all configuration and populates, validates and starts each
+// Feature. Load is idempotent; subsequent calls do nothing but return the
+// result of the first. Use Reload to load configuration anew.
 func (c *Config) Load(ctx context.Context) error {
-	if c.file != "" {
-		log.Infof("Ignoring config path in lieu of: %s", c.file)
-		c.SetConfigFile(c.file)
-	} else {
-		for _, d := range c.path {
-			log.Infof("Updating config search path: %q", d)
-			c.AddConfigPath(filepath.Clean(d))
-		}
+	if c.nwerr != nil {
+		return c.nwerr
 	}
 
-	c.AddConfigPath(".")
-	c.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
-	c.AutomaticEnv()
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
 
-	if err := c.readConfig(); err != nil {
+	if len(c.helpFeats) > 0 {
+		c.helpFeatures()
+	}
+
+	if c.check != "" {
+		c.configCheck(ctx)
+	}
+
+	if !c.loaded {
+		c.mu.Lock()
+		c.loaded = true
+		c.mu.Unlock()
+
+		c.lderr = c.load(ctx)
+	}
+
+	return c.lderr
+}
+
+// Reload loads configuration anew (using the same flags) into a new set of
+// Features. If all of these are successfully validated, they replace the
+// current Features: those previously started are closed (see Close) and the
+// new ones are started. Otherwise, the current configuration and Features
+// are left untouched and the error is returned.
+//
+// Note that the underlying viper is replaced; any changes made directly to
+// it (other than AddConfigPath) are lost. Values retrieved directly from the
+// embedded viper are not protected from a concurrent Reload.
+func (c *Config) Reload(ctx context.Context) error {
+	if c.nwerr != nil {
+		return c.nwerr
+	}
+
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	c.mu.RLock()
+	n := c.fork()
+	c.mu.RUnlock()
+
+	if err := n.populate(ctx); err != nil {
 		return err
 	}
 
-	c.flags.Visit(func(f *pflag.Flag) {
-		// Only bind changed, non-hidden flags
-		if f.Changed && !f.Hidden {
-			c.BindPFlag(f.Name, f)
+	// The current Features are closed before the new ones are started; the
+	// new ones are only published (by swap) once they have been started.
+	c.mu.Lock()
+	old := c.active
+	c.active = nil
+	c.mu.Unlock()
+
+	if err := closeFeatures(ctx, old); err != nil {
+		log.Warningf("Error closing features for reload: %v", err)
+	}
+
+	n.adoptBase()
+	err := n.start(ctx)
+
+	c.mu.Lock()
+	c.swap(n)
+	c.lderr = err
+	c.mu.Unlock()
+
+	return err
+}
+
+// fork returns a new, unloaded Config sharing this Config's flags and
+// options but holding its own instance of each Feature, so that it may be
+// loaded without disturbing this Config or its (possibly running) Features.
+// The caller must hold c.mu (for reading).
+func (c *Config) fork() *Config {
+	n := &Config{
+		name:     c.name,
+		file:     c.file,
+		path:     c.path,
+		xpath:    c.xpath,
+		envFiles: c.envFiles,
+		sets:     c.sets,
+		fmap:     make(map[Label]Feature),
+		oomap:    make(map[string]Label),
+		ooset:    c.ooset,
+		sels:     c.sels,
+		alias:    c.alias,
+		flags:    c.flags,
+		opts:     c.opts,
+		report:   new(LoadReport),
+		nwerr:    c.nwerr,
+		Viper:    newViper(c.name, c.opts.envPrefix),
+	}
+
+	for _, fd := range c.defs {
+		nd := fd.fork()
+		if nd.create != nil {
+			n.fmap[nd.label] = nd.Feature
 		}
-	})
+		n.defs = append(n.defs, nd)
+	}
 
-	// TODO: Rewrite this comment
-	// `all` and `oom` are used to determine whether a particular "oneof" has
-	// already been configured. `all` is a map[string]interface{} containing all
-	// currently available key/val pairs while `oom` is the "one of map" used for
-	// marking previously encountered "oneof" names.
-	all := c.AllSettings()
+	return n
+}
+
+// swap replaces this Config's loaded state (and Features) with that of n,
+// including n's started Features. The caller must hold c.mu.
+func (c *Config) swap(n *Config) {
+	c.Viper = n.Viper
+	c.raw = n.raw
+	c.fdata = n.fdata
+	c.dotenv = n.dotenv
+	c.dotKeys = n.dotKeys
+	c.bundle = n.bundle
+	c.spath = n.spath
+	c.setKeys = n.setKeys
+	c.defs = n.defs
+	c.fmap = n.fmap
+	c.oomap = n.oomap
+	c.report = n.report
+	c.active = n.active
+	c.loaded = true
+	c.lderr = nil
+}
+
+// adoptBase copies the config values loaded into a forked base Feature back
+// into the original (which the caller holds directly) and puts the original
+// back in its place. It is only called on a fork that is not yet published.
+func (c *Config) adoptBase() {
+	if c.opts.base == nil {
+		return
+	}
 
 	for _, fd := range c.defs {
-		// If a feature's label is found in the `all` map (meaning we have config
-		// values for that Feature) *and* that feature has a non-empty "oneof"
-		// name, then this is the configured Feature for that "oneof" set.
-		// There can be only one of these per "oneof" name.
-		if _, has := all[string(fd.label)]; has && fd.oneof != "" {
-			if oo, ok := c.oomap[fd.oneof]; ok {
-				delete(c.oomap, fd.oneof)
-				return multipleOneOfError(fd.oneof, oo, fd.label)
-			}
-			log.Infof("Using %q as %q", fd.label, fd.oneof)
-			c.oomap[fd.oneof] = fd.label
+		if fd.create == nil && fd.Feature != c.opts.base {
+			fd.copyFields(c.opts.base)
+			fd.Feature = c.opts.base
 		}
+	}
+}
 
-		for k, v := range fd.defaults {
-			c.SetDefault(k, v)
+func (c *Config) load(ctx context.Context) error {
+	if err := c.populate(ctx); err != nil {
+		return err
+	}
+
+	return c.start(ctx)
+}
+
+// populate loads all config settings then populates and validates each
+// enabled (and selected) Feature.
+func (c *Config) populate(ctx context.Context) error {
+	all, err := c.loadSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.selectOneOfs(all); err != nil {
+		return err
+	}
+
+	for _, fd := range c.defs {
+		if s := c.inactive(fd); s != "" {
+			if s == CheckDisabled {
+				log.Infof("Feature %q is disabled", fd.label)
+			}
+			continue
 		}
 
-		if err := c.unmarshal(fd); err != nil {
+		if err := c.populateFeature(ctx, fd); err != nil {
 			return err
 		}
+	}
 
-		// We skip the call to Validate for oneof Features that are not currently
-		// configured.
-		if fd.oneof == "" || c.oomap[fd.oneof] != "" {
-			if err := fd.Validate(ctx); err != nil {
-				return err
-			}
+	return nil
+}
+
+// inactive returns CheckUnselected for oneof Features that are not currently
+// selected, CheckDisabled for disabled Features, or the empty string for all
+// others. Inactive Features are skipped entirely; they are neither populated,
+// validated nor started and any config values left for them are ignored.
+func (c *Config) inactive(fd *featureDefn) string {
+	switch {
+	case fd.oneof != "" && c.oomap[fd.oneof] != fd.label:
+		return CheckUnselected
+	case fd.disabled:
+		return CheckDisabled
+	default:
+		return ""
+	}
+}
+
+// populateFeature unmarshals the config values for a single, active Feature,
+// checks its enum constraints and then validates it. This is shared by Load
+// and Check so that the two cannot disagree.
+func (c *Config) populateFeature(ctx context.Context, fd *featureDefn) error {
+	if err := c.unmarshal(fd); err != nil {
+		return c.decodeError(fd, err)
+	}
+
+	fd.validated = true
+	if fd.verr = fd.checkEnums(); fd.verr == nil {
+		fd.verr = fd.Validate(ctx)
+	}
+
+	return fd.verr
+}
+
+// loadSettings reads and merges all config settings (and defaults) into the
+// underlying viper config and returns the settings captured before defaults
+// were applied.
+func (c *Config) loadSettings(ctx context.Context) (map[string]interface{}, error) {
+	c.SetEnvKeyReplacer(envKeyReplacer)
+	c.AutomaticEnv()
+
+	c.report = new(LoadReport)
+
+	if err := c.readConfig(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkKeys(); err != nil {
+		return nil, err
+	}
+
+	if err := c.bindFlags(); err != nil {
+		return nil, err
+	}
+
+	if err := c.bindEnvAliases(); err != nil {
+		return nil, err
+	}
+
+	if err := c.applySets(); err != nil {
+		return nil, err
+	}
+
+	c.applyEnvFiles()
+
+	// Note that `all` is captured before any defaults are set.
+	all := c.AllSettings()
+
+	for _, fd := range c.defs {
+		fd.configured = fd.configuredIn(all)
+
+		for k, v := range fd.defaults {
+			c.SetDefault(k, v)
 		}
 	}
 
-	return nil
+	c.setBundleDefaults()
+
+	for _, fd := range c.defs {
+		fd.disabled = !c.GetBool(fd.label.Key(enabledKey)) && fd.optional()
+	}
+
+	return all, nil
 }
 
+// OneOf returns the Feature currently selected for the "oneof" set with the
+// given name -- or nil if none is selected.
 func (c *Config) OneOf(name string) Feature {
-	return c.Feature(c.oomap[name])
+	c.mu.RLock()
+	l := c.oomap[name]
+	c.mu.RUnlock()
+
+	return c.Feature(l)
 }
 
 // TODO(tep): Add a struct tag option to indicate required params.
@@ -231,30 +537,214 @@ func isZero(in interface{}) bool {
 	return reflect.DeepEqual(v.Interface(), reflect.Zero(t).Interface())
 }
 
-func (c *Config) readConfig() error {
-	err := c.readConfigData()
+func (c *Config) bindFlags() error {
+	var err error
+
+	c.flags.Visit(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+
+		// Alias flags (which are hidden) are bound to their canonical key.
+		if ak, ok := c.alias[f.Name]; ok {
+			if kf := c.flags.Lookup(ak); kf != nil && kf.Changed {
+				if err == nil {
+					err = aliasConflictError(ak, f.Name, originFlag)
+				}
+				return
+			}
+			c.useAlias(f.Name, ak, originFlag)
+			c.BindPFlag(ak, f)
+			return
+		}
+
+		// Otherwise, only bind non-hidden flags
+		if f.Hidden {
+			return
+		}
+
+		key := f.Name
+
+		// Selector flags are bound to their selector key
+		if sk, ok := c.sels[f.Name]; ok {
+			key = sk
+		}
+
+		c.BindPFlag(key, f)
+	})
+
+	return err
+}
+
+// readConfig reads the config file (or reader) and all other Sources into a
+// raw settings map which, after being massaged as necessary, is merged into
+// the underlying viper config.
+func (c *Config) readConfig(ctx context.Context) error {
+	file, err := c.readConfigData(ctx)
 	if err == nil {
-		log.Infof("Config loaded from: %q", c.ConfigFileUsed())
-		return nil
+		if f := c.ConfigFileUsed(); f != "" {
+			log.Infof("Config loaded from: %q", f)
+			c.report.Sources = append(c.report.Sources, f)
+		}
+	} else if _, ok := err.(*ConfigParseError); ok {
+		if c.opts.invalid == RejectInvalidConfigFile {
+			return err
+		}
+		log.Warningf("Ignoring invalid configuration file: %v", err)
+		file = nil
+	} else {
+		switch c.opts.ocfErr {
+		case RequireConfigFile:
+			return err
+		case WarnOnConfigFileErrors:
+			log.Warningf("Error loading configuration file: %v", err)
+		}
+		file = nil
 	}
 
-	switch c.opts.ocfErr {
-	case RequireConfigFile:
+	if len(c.opts.readers) > 0 && c.opts.rdrMode == LayerReaders {
+		if file == nil {
+			file = make(map[string]interface{})
+		}
+
+		if err := c.readReaders(ctx, file); err != nil {
+			return err
+		}
+	}
+
+	raw, err := c.mergeSources(ctx, file)
+	if err != nil {
 		return err
-	case WarnOnConfigFileErrors:
-		log.Warningf("Error loading configuration file: %v", err)
-		fallthrough
-	default:
+	}
+
+	if err := c.readEnvFiles(); err != nil {
+		return err
+	}
+
+	if err := c.migrate(raw); err != nil {
+		return err
+	}
+
+	// The file's own settings are migrated too so that values may still be
+	// attributed to it (see rawOrigin); since these are used for nothing
+	// else, any error is ignored.
+	if c.fdata != nil {
+		c.applyMigrations(c.fdata, false)
+	}
+
+	if err := c.resolveAliases(raw, originFile); err != nil {
+		return err
+	}
+
+	c.raw = raw
+
+	if err := c.readDefaultConfig(ctx); err != nil {
+		return err
+	}
+
+	return c.MergeConfigMap(normalize(raw).(map[string]interface{}))
+}
+
+// readDefaultConfig reads the settings provided by the DefaultConfig option
+// (if any) which, like config file data, are migrated and have their aliases
+// resolved. These are later applied as defaults; see setBundleDefaults.
+func (c *Config) readDefaultConfig(ctx context.Context) error {
+	src := c.opts.defConfig
+	if src == nil {
 		return nil
 	}
+
+	m, err := src.Settings(ctx)
+	if err != nil {
+		return sourceError(src.Name(), err)
+	}
+
+	bundle := make(map[string]interface{})
+	mergeSettings(bundle, m)
+
+	if err := c.migrate(bundle); err != nil {
+		return err
+	}
+
+	if err := c.resolveAliases(bundle, originBundle); err != nil {
+		return err
+	}
+
+	c.bundle = bundle
+	c.report.Sources = append([]string{src.Name()}, c.report.Sources...)
+
+	return nil
+}
+
+// setBundleDefaults sets each value read by readDefaultConfig as the default
+// for its key; these replace the defaults taken from struct tags.
+func (c *Config) setBundleDefaults() {
+	for _, k := range flattenKeys(c.bundle, "") {
+		if v, ok := lookupKey(c.bundle, k); ok {
+			c.SetDefault(k, v)
+		}
+	}
+}
+
+// readConfigData locates and parses the config file and returns the raw
+// settings found there -- unless reader data replaces the file search, in
+// which case that is returned instead. If no config file can be found, a
+// *ConfigNotFoundError is returned; if it cannot be parsed, the error is a
+// *ConfigParseError.
+func (c *Config) readConfigData(ctx context.Context) (map[string]interface{}, error) {
+	if len(c.opts.readers) > 0 && c.opts.rdrMode == ReplaceFileSearch {
+		data := make(map[string]interface{})
+		if err := c.readReaders(ctx, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	file := expandPath(c.file)
+
+	if file != "" {
+		log.Infof("Ignoring config path in lieu of: %s", file)
+	} else {
+		var err error
+		c.spath = c.searchPath()
+		if file, err = findConfigFile(c.name, c.spath); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := readConfigFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetConfigFile(file)
+
+	// Keep a copy of the file's own settings (since data may be modified by
+	// later layers) so values may be attributed to it.
+	c.fdata = make(map[string]interface{})
+	mergeSettings(c.fdata, data)
+
+	return data, nil
 }
 
-func (c *Config) readConfigData() error {
-	if cr := c.opts.cfgReader; cr != nil {
-		c.SetConfigType(cr.typ)
-		return c.ReadConfig(cr.readr)
+// readReaders merges the data from each FromReader option into data.
+func (c *Config) readReaders(ctx context.Context, data map[string]interface{}) error {
+	for _, r := range c.opts.readers {
+		m, err := r.Settings(ctx)
+		if err != nil {
+			if pe, ok := err.(*ConfigParseError); ok && pe.File == "" {
+				pe.File = r.Name()
+			}
+			return err
+		}
+
+		log.Infof("Config loaded from: %s", r.Name())
+		c.report.Sources = append(c.report.Sources, r.Name())
+
+		mergeSettings(data, m)
 	}
-	return c.ReadInConfig()
+
+	return nil
 }
 
 func tagname(c *mapstructure.DecoderConfig) {
@@ -273,37 +763,60 @@ func (c *Config) unmarshal(fd *featureDefn) error {
 	// What follows is a kludge to work around viper bug #188 that wreaks
 	// havok between ENV overrides and `UnmarshalKey` (amongst other things).
 	//
-	// The gist is: we call `c.Get` on the viper-key constructed from each of
-	// the map keys returned by "Get"ing the raw map for this feature - if
-	// this key's `Get` value differs from the raw map's value, we force an
-	// override to the former.
+	// The gist is: we call `c.Get` on the viper-key for each of this
+	// feature's fields and force an override to the returned value. Note
+	// that all keys must be overridden; once any key is, "Get"ing the raw
+	// map for this feature returns only those from the override layer.
 	//
 	// IOW: `c.Get("foo")["bar"]` might be wrong (it ignores "${FOO_BAR}")
 	// but `c.Get("foo.bar")` is correct (i.e. it will be "${FOO_BAR}" if its
-	// set) -- so, we compare the two and if they're different we force the
-	// value for "foo.bar" to be what's returned by `c.Get("foo.bar")`.
+	// set) -- so, we force the value for "foo.bar" to be what's returned by
+	// `c.Get("foo.bar")`.
 	//
 	// Once we do this, `c.UnmarshalKey(...)` will do the right thing.
 	//
 	ls := string(fd.label)
 
-	if fm, ok := c.Get(ls).(map[string]interface{}); ok {
-		for k := range fm {
-			fk := fd.label.Key(k)
-			if kv := c.Get(fk); kv != fm[fk] {
-				c.Set(fk, kv)
-			}
+	for _, fk := range fd.keys() {
+		if kv := c.Get(fk); kv != nil {
+			c.Set(fk, kv)
 		}
 	}
 
 	return c.UnmarshalKey(ls, fd.Feature, tagname)
 }
 
+// Feature returns the Feature with the given label -- or nil if no such
+// Feature exists or it has been disabled.
 func (c *Config) Feature(l Label) Feature {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if fd := c.defn(l); fd != nil && fd.disabled {
+		return nil
+	}
+
 	return c.fmap[l]
 }
 
+func (c *Config) defn(l Label) *featureDefn {
+	for _, fd := range c.defs {
+		if fd.label == l {
+			return fd
+		}
+	}
+	return nil
+}
+
+// Features returns the sorted list of labels for all non-base Features.
 func (c *Config) Features() []Label {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.features()
+}
+
+func (c *Config) features() []Label {
 	var i int
 	list := make([]Label, len(c.fmap))
 
@@ -312,19 +825,17 @@ func (c *Config) Features() []Label {
 		i++
 	}
 
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+
 	return list
 }
 
 // BinDir is a wrapper around filepath.Join returning a file path constructed
 // by joining the directory location of the current program's binary with the
-// provided subdirectory components.
+// provided subdirectory components. The binary's location is determined by
+// os.Executable with all symlinks resolved.
 func BinDir(subdirs ...string) string {
-	return filepath.Clean(filepath.Join(append([]string{filepath.Dir(os.Args[0])}, subdirs...)...))
-}
-
-func defaultCfgPath() []string {
-	d := BinDir("conf")
-	return []string{filepath.Dir(d), d}
+	return filepath.Clean(filepath.Join(append([]string{binDir()}, subdirs...)...))
 }
 
 func stringify(i interface{}) string {
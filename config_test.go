@@ -22,7 +22,13 @@ package basecfg
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
 
 	// "github.com/kr/pretty"
@@ -78,3 +84,334 @@ func TestIgnore(t *testing.T) {
 		t.Errorf("bc.Load() == %v; Wanted %v", err, nil)
 	}
 }
+
+func TestReadConfig(t *testing.T) {
+	defer useTestRegistry()()
+
+	tmp, err := ioutil.TempDir("", "basecfg-readconfig-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	dirA := filepath.Join(tmp, "a")
+	dirB := filepath.Join(tmp, "b")
+
+	for dir, name := range map[string]string{dirA: "alpha", dirB: "bravo"} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		data := []byte("name: " + name + "\nport: 1234\n")
+		if err := ioutil.WriteFile(filepath.Join(dir, "readcfg.yml"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		extra []string
+		want  string
+		file  string
+	}{
+		{"config-path", []string{"--config-path", dirB}, nil, "bravo", dirB},
+		{"added-first", []string{"--config-path", dirB}, []string{dirA}, "alpha", dirA},
+		{"config-file", []string{"--config-file", filepath.Join(dirB, "readcfg.yml")}, []string{dirA}, "bravo", dirB},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bc := new(baseConfig)
+			bc.Config = New("readcfg", Base(bc))
+
+			if err := bc.flags.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, d := range tc.extra {
+				bc.AddConfigPath(d)
+			}
+
+			if err := bc.Load(context.Background()); err != nil {
+				t.Fatalf("Load() == %v; Wanted %v", err, nil)
+			}
+
+			if bc.Name != tc.want {
+				t.Errorf("Name == %q; Wanted %q", bc.Name, tc.want)
+			}
+
+			if got, want := bc.ConfigFileUsed(), filepath.Join(tc.file, "readcfg.yml"); got != want {
+				t.Errorf("ConfigFileUsed() == %q; Wanted %q", got, want)
+			}
+
+			raw := map[string]interface{}{"name": tc.want, "port": 1234}
+			if !reflect.DeepEqual(bc.raw, raw) {
+				t.Errorf("raw == %#v; Wanted %#v", bc.raw, raw)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	RegisterOneOf("otf", "feata", func() Feature { return new(oneofFeatureA) })
+	RegisterOneOf("otf", "featb", func() Feature { return new(oneofFeatureB) })
+
+	dir, err := ioutil.TempDir("", "basecfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "reload.json")
+
+	write := func(text string) {
+		if err := ioutil.WriteFile(file, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{ "feata": { "option": "one" } }`)
+
+	c := New("reloadtest")
+	c.flags.Parse([]string{"--config-file", file})
+
+	ctx := context.Background()
+
+	// Calling Load more than once should be harmless
+	for i := 0; i < 2; i++ {
+		if err := c.Load(ctx); err != nil {
+			t.Fatalf("c.Load() #%d == %v; Wanted %v", i+1, err, nil)
+		}
+	}
+
+	if got, want := c.OneOf("otf"), (&oneofFeatureA{Option: "one"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("c.OneOf(%q) == %#v; Wanted %#v", "otf", got, want)
+	}
+
+	write(`{ "featb": { "option": "two" } }`)
+
+	if err := c.Reload(ctx); err != nil {
+		t.Fatalf("c.Reload() == %v; Wanted %v", err, nil)
+	}
+
+	if got, want := c.OneOf("otf"), (&oneofFeatureB{Option: "two"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("c.OneOf(%q) == %#v; Wanted %#v", "otf", got, want)
+	}
+}
+
+type reloadFeature struct {
+	Level  int `cfg:"level"`
+	closed bool
+}
+
+func (rf *reloadFeature) FlagSet(*pflag.FlagSet) {}
+
+func (rf *reloadFeature) Validate(context.Context) error {
+	if rf.Level > 3 {
+		return errors.New("level too high")
+	}
+	return nil
+}
+
+func (rf *reloadFeature) Close() error {
+	rf.closed = true
+	return nil
+}
+
+func TestReloadFailure(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("rl", func() Feature { return new(reloadFeature) })
+
+	dir, err := ioutil.TempDir("", "basecfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "reload.json")
+
+	write := func(text string) {
+		if err := ioutil.WriteFile(file, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{ "name": "one", "rl": { "level": 1 } }`)
+
+	bc := new(baseConfig)
+	bc.Config = New("reloadtest", Base(bc))
+	bc.flags.Parse([]string{"--config-file", file})
+
+	ctx := context.Background()
+
+	if err := bc.Load(ctx); err != nil {
+		t.Fatalf("bc.Load() == %v; Wanted %v", err, nil)
+	}
+
+	orig := bc.Feature("rl").(*reloadFeature)
+
+	write(`{ "name": "two", "rl": { "level": 9 } }`)
+
+	if err := bc.Reload(ctx); err == nil {
+		t.Fatalf("bc.Reload() == nil; Wanted error")
+	}
+
+	if got := bc.Feature("rl").(*reloadFeature); got != orig || got.Level != 1 || got.closed {
+		t.Errorf("after failed Reload: c.Feature(%q) == %#v; Wanted original, open Feature with Level 1", "rl", got)
+	}
+
+	if bc.Name != "one" || bc.GetInt("rl.level") != 1 {
+		t.Errorf("after failed Reload: Name == %q, rl.level == %d; Wanted %q, %d", bc.Name, bc.GetInt("rl.level"), "one", 1)
+	}
+
+	write(`{ "name": "three", "rl": { "level": 2 } }`)
+
+	if err := bc.Reload(ctx); err != nil {
+		t.Fatalf("bc.Reload() == %v; Wanted %v", err, nil)
+	}
+
+	if got := bc.Feature("rl").(*reloadFeature); got == orig || got.Level != 2 || got.closed || !orig.closed {
+		t.Errorf("after Reload: c.Feature(%q) == %#v (original closed: %t); Wanted new, open Feature with Level 2", "rl", got, orig.closed)
+	}
+
+	if bc.Name != "three" {
+		t.Errorf("after Reload: Name == %q; Wanted %q", bc.Name, "three")
+	}
+}
+
+func TestReloadConcurrent(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("rl", func() Feature { return new(reloadFeature) })
+	Register("opt", func() Feature { return new(optionalFeature) })
+
+	dir, err := ioutil.TempDir("", "basecfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "reload.json")
+	if err := ioutil.WriteFile(file, []byte(`{ "name": "one", "rl": { "level": 1 } }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bc := new(baseConfig)
+	bc.Config = New("reloadtest", Base(bc), EffectiveHelp)
+	bc.flags.Parse([]string{"--config-file", file})
+
+	ctx := context.Background()
+
+	if err := bc.Load(ctx); err != nil {
+		t.Fatalf("bc.Load() == %v; Wanted %v", err, nil)
+	}
+
+	readers := []func(){
+		func() { bc.Enabled("opt") },
+		func() { bc.Features() },
+		func() { bc.Feature("rl") },
+		func() { bc.FeatureInfos() },
+		func() { bc.Usage(ioutil.Discard) },
+		func() { bc.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil)) },
+		func() { bc.Close(ctx) },
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := bc.Reload(ctx); err != nil {
+					t.Errorf("bc.Reload() == %v; Wanted %v", err, nil)
+				}
+			}
+		}()
+	}
+
+	for _, read := range readers {
+		wg.Add(1)
+		go func(read func()) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				read()
+			}
+		}(read)
+	}
+
+	wg.Wait()
+
+	if got := bc.Feature("rl").(*reloadFeature); got.Level != 1 {
+		t.Errorf("bc.Feature(%q).Level == %d; Wanted %d", "rl", got.Level, 1)
+	}
+}
+
+type nodefaultBase struct {
+	Name  string `cfg:"name"`
+	Level int    `cfg:"level,nodefault"`
+
+	*Config
+}
+
+func (nb *nodefaultBase) FlagSet(*pflag.FlagSet)         {}
+func (nb *nodefaultBase) Validate(context.Context) error { return nil }
+
+func TestReloadBase(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	dir, err := ioutil.TempDir("", "basecfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "reload.json")
+
+	write := func(text string) {
+		if err := ioutil.WriteFile(file, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{ "name": "one" }`)
+
+	nb := &nodefaultBase{Level: 5}
+	nb.Config = New("reloadtest", Base(nb))
+	nb.flags.Parse([]string{"--config-file", file})
+
+	ctx := context.Background()
+
+	if err := nb.Load(ctx); err != nil {
+		t.Fatalf("nb.Load() == %v; Wanted %v", err, nil)
+	}
+
+	steps := []struct {
+		text  string
+		name  string
+		level int
+	}{
+		{`{ "name": "one" }`, "one", 5},
+		{`{ "name": "two", "level": 7 }`, "two", 7},
+		{`{ "name": "three" }`, "three", 5},
+	}
+
+	for _, s := range steps {
+		write(s.text)
+
+		if err := nb.Reload(ctx); err != nil {
+			t.Fatalf("nb.Reload() with %s == %v; Wanted %v", s.text, err, nil)
+		}
+
+		if nb.Name != s.name || nb.Level != s.level {
+			t.Errorf("after Reload with %s: Name == %q, Level == %d; Wanted %q, %d", s.text, nb.Name, nb.Level, s.name, s.level)
+		}
+	}
+}
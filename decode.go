@@ -0,0 +1,146 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeError attempts to identify the config key whose value caused err
+// (an error returned while unmarshaling fd) by decoding each of fd's fields
+// individually. If found, a *DecodeError is returned -- including the file
+// and line number where the value was set (if the value is known to have
+// come from the config file). Otherwise, err is returned as is. The values
+// of secret fields are masked.
+func (c *Config) decodeError(fd *featureDefn, err error) error {
+	for _, fi := range fd.fields {
+		key := fd.label.Key(fi.key)
+
+		val := c.Get(key)
+		if val == nil {
+			continue
+		}
+
+		typ := fd.fieldType(fi)
+		if typ == nil {
+			continue
+		}
+
+		_, derr := decodeValue(val, typ)
+		if derr == nil {
+			continue
+		}
+
+		de := &DecodeError{
+			Label:      fd.label,
+			Key:        key,
+			TargetType: typ,
+		}
+
+		if c.fromFile(key) {
+			de.File = c.ConfigFileUsed()
+			de.Line = keyLine(de.File, key)
+		}
+
+		// mapstructure refers to our (unnamed) value as ''
+		msg := strings.Replace(derr.Error(), "'' ", "", 1)
+
+		if c.isSecret(key) {
+			// ...and may include the value itself.
+			msg = strings.Replace(msg, fmt.Sprint(val), secretMask, -1)
+			val = secretMask
+		}
+
+		de.Value = val
+
+		var loc string
+		switch {
+		case de.File != "" && de.Line > 0:
+			loc = fmt.Sprintf("%s:%d: ", de.File, de.Line)
+		case de.File != "":
+			loc = de.File + ": "
+		}
+
+		de.error = fmt.Errorf("%sinvalid value %#v for %q (want %v): %s", loc, val, key, typ, msg)
+
+		return de
+	}
+
+	return err
+}
+
+// fromFile returns true if the current value for key was read from the
+// config file -- as opposed to a Source, reader or DefaultConfig data.
+func (c *Config) fromFile(key string) bool {
+	return c.origin(key) == originFile && c.ConfigFileUsed() != ""
+}
+
+// keyLine returns the line number at which the given (dot separated) key is
+// defined in the named YAML (or JSON) file -- or zero if this cannot be
+// determined.
+func keyLine(file, key string) int {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return 0
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return 0
+	}
+
+	node := doc.Content[0]
+	line := 0
+
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return 0
+		}
+
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, part) {
+				line = node.Content[i].Line
+				next = node.Content[i+1]
+				break
+			}
+		}
+
+		if next == nil {
+			return 0
+		}
+
+		node = next
+	}
+
+	return line
+}
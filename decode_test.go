@@ -0,0 +1,124 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type pinFeature struct {
+	Pin int64 `cfg:"pin,secret"`
+}
+
+func (pf *pinFeature) FlagSet(*pflag.FlagSet)         {}
+func (pf *pinFeature) Validate(context.Context) error { return nil }
+
+func TestDecodeError(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+	Register("vault", func() Feature { return new(pinFeature) })
+
+	dir, err := ioutil.TempDir("", "basecfg-decodetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name string
+		text string
+		line int
+	}{
+		{"decodetest.yaml", "# comment\nfeat:\n  thing-one: ok\n  other: abc\n", 4},
+		{"decodetest.json", "{\n  \"feat\": {\n    \"Other\": \"abc\"\n  }\n}\n", 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(dir, tc.name)
+			if err := ioutil.WriteFile(file, []byte(tc.text), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			c := New("decodetest")
+			c.file = file
+
+			err := c.Load(context.Background())
+
+			de, ok := err.(*DecodeError)
+			if !ok {
+				t.Fatalf("c.Load() == %v; Wanted %T", err, de)
+			}
+
+			want := &DecodeError{Label: "feat", Key: "feat.other", Value: "abc", TargetType: reflect.TypeOf(int64(0)), File: file, Line: tc.line}
+			want.error = de.error
+
+			if !reflect.DeepEqual(de, want) {
+				t.Errorf("c.Load() == %#v; Wanted %#v", de, want)
+			}
+		})
+	}
+
+	// A value from a Source is not attributed to the config file
+	file := filepath.Join(dir, "decodetest.yaml")
+	if err := ioutil.WriteFile(file, []byte("feat:\n  other: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := MapSource("map", map[string]interface{}{"feat": map[string]interface{}{"other": "abc"}})
+
+	c := New("decodetest", WithSource(src, RequireConfigFile))
+	c.file = file
+
+	if de, ok := c.Load(context.Background()).(*DecodeError); !ok || de.Key != "feat.other" || de.File != "" || de.Line != 0 {
+		t.Errorf("c.Load() == %#v; Wanted %T for %q without file", de, de, "feat.other")
+	}
+
+	os.Setenv("DECODETEST_FEAT_OTHER", "xyz")
+	defer os.Unsetenv("DECODETEST_FEAT_OTHER")
+
+	c = New("decodetest", IgnoreConfigFileErrors, ConfigPath(dir+"/none"))
+
+	if de, ok := c.Load(context.Background()).(*DecodeError); !ok || de.Key != "feat.other" || de.File != "" || de.Line != 0 {
+		t.Errorf("c.Load() == %#v; Wanted %T for %q without file", de, de, "feat.other")
+	}
+
+	os.Unsetenv("DECODETEST_FEAT_OTHER")
+	os.Setenv("DECODETEST_VAULT_PIN", "hunter2")
+	defer os.Unsetenv("DECODETEST_VAULT_PIN")
+
+	c = New("decodetest", IgnoreConfigFileErrors, ConfigPath(dir+"/none"))
+
+	de, ok := c.Load(context.Background()).(*DecodeError)
+	if !ok || de.Key != "vault.pin" || de.Value != secretMask || strings.Contains(de.Error(), "hunter2") {
+		t.Errorf("c.Load() == %#v; Wanted %T for %q with masked value", de, de, "vault.pin")
+	}
+}
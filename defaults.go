@@ -22,6 +22,7 @@ package basecfg
 
 import (
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -35,17 +36,29 @@ func (fd *featureDefn) extractDefaults() {
 	}
 
 	fd.defaults = make(map[string]interface{})
+	fd.fields = nil
 
 	for i := 0; i < t.NumField(); i++ {
-		if fi := getFieldInfo(t, i); fi != nil {
+		fi := getFieldInfo(t, i)
+		if fi == nil {
+			continue
+		}
+
+		fd.fields = append(fd.fields, fi)
+
+		if !fi.nodefault {
 			fd.defaults[fd.label.Key(fi.key)] = v.FieldByName(fi.name).Interface()
 		}
 	}
 }
 
 type fieldInfo struct {
-	name string
-	key  string
+	name      string
+	key       string
+	nodefault bool
+	secret    bool
+	aliases   []string
+	enum      []string
 }
 
 func getFieldInfo(t reflect.Type, i int) *fieldInfo {
@@ -62,15 +75,82 @@ func getFieldInfo(t reflect.Type, i int) *fieldInfo {
 	}
 
 	parts := strings.Split(tag, ",")
-	key := parts[0]
+	fi := &fieldInfo{name: sf.Name, key: parts[0]}
+
+	for _, p := range parts[1:] {
+		switch p {
+		case "nodefault":
+			fi.nodefault = true
+		case "secret":
+			fi.secret = true
+		default:
+			switch {
+			case strings.HasPrefix(p, "alias="):
+				fi.aliases = append(fi.aliases, strings.TrimPrefix(p, "alias="))
+			case strings.HasPrefix(p, "enum="):
+				fi.enum = strings.Split(strings.TrimPrefix(p, "enum="), "|")
+			}
+		}
+	}
+
+	return fi
+}
+
+// keys returns the fully qualified config keys for all of this Feature's
+// `cfg` tagged fields.
+func (fd *featureDefn) keys() []string {
+	list := make([]string, len(fd.fields))
+	for i, fi := range fd.fields {
+		list[i] = fd.label.Key(fi.key)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
 
-	if len(parts) > 1 {
-		for _, p := range parts[1:] {
-			if p == "nodefault" {
-				return nil
+// checkEnums returns an *EnumError if the value of any field tagged with an
+// "enum" constraint is not one of its allowed values. Unset (i.e. zero
+// valued) fields are not checked. For slices, each element is checked.
+func (fd *featureDefn) checkEnums() error {
+	v := reflect.Indirect(reflect.ValueOf(fd.Feature))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, fi := range fd.fields {
+		if len(fi.enum) == 0 {
+			continue
+		}
+
+		fv := v.FieldByName(fi.name)
+		if isZero(fv.Interface()) {
+			continue
+		}
+
+		vals := []reflect.Value{fv}
+		if fv.Kind() == reflect.Slice {
+			vals = vals[:0]
+			for i := 0; i < fv.Len(); i++ {
+				vals = append(vals, fv.Index(i))
+			}
+		}
+
+		for _, ev := range vals {
+			if s := stringify(ev.Interface()); !hasString(fi.enum, s) {
+				return enumError(fd.label.Key(fi.key), s, fi.enum)
 			}
 		}
 	}
 
-	return &fieldInfo{sf.Name, key}
+	return nil
+}
+
+func hasString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
 }
@@ -23,6 +23,7 @@ package basecfg
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/kr/pretty"
@@ -100,3 +101,44 @@ func TestFeatureFlagDefaults(t *testing.T) {
 		t.Errorf("incorrect flag default values: Got(%# v); Wanted(%# v)", pretty.Formatter(got), pretty.Formatter(want))
 	}
 }
+
+type enumFeature struct {
+	Mode   string   `cfg:"mode,enum=fast|slow"`
+	Levels []string `cfg:"levels,nodefault,enum=low|high"`
+	Token  string   `cfg:"token,secret"`
+}
+
+func (ef *enumFeature) FlagSet(fs *pflag.FlagSet) {
+	fs.StringVar(&ef.Mode, "mode", "", "Processing mode")
+}
+
+func (ef *enumFeature) Validate(context.Context) error { return nil }
+
+func TestEnums(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("proc", func() Feature { return &enumFeature{Mode: "fast", Token: "xyzzy"} })
+	Register("bare", func() Feature { return new(enumFeature) })
+
+	tests := []struct {
+		text string
+		ok   bool
+	}{
+		{`{}`, true},
+		{`{ "bare": { "mode": "slow" } }`, true},
+		{`{ "bare": { "mode": "bogus" } }`, false},
+		{`{ "proc": { "mode": "slow", "levels": ["low", "high"] } }`, true},
+		{`{ "proc": { "mode": "medium" } }`, false},
+		{`{ "proc": { "levels": ["low", "mid"] } }`, false},
+	}
+
+	for _, tc := range tests {
+		c := New("enumtest", FromReader("json", strings.NewReader(tc.text)))
+
+		err := c.Load(context.Background())
+		if _, isEnum := err.(*EnumError); (err == nil) != tc.ok || (err != nil && !isEnum) {
+			t.Errorf("%s: c.Load() == %v; Wanted ok=%t", tc.text, err, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change describes how a single config key differs between two Configs.
+type Change int
+
+const (
+	Added Change = iota + 1
+	Removed
+	Modified
+)
+
+func (ch Change) String() string {
+	switch ch {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return fmt.Sprintf("Change(%d)", int(ch))
+	}
+}
+
+// KeyChange describes a config key that differs between two Configs. Label
+// is the label of the Feature owning Key (or the empty string for the base
+// Feature or keys not owned by any Feature). Old is nil for Added keys and New
+// is nil for Removed keys. The values of secret fields are masked.
+type KeyChange struct {
+	Label  Label
+	Key    string
+	Change Change
+	Old    interface{}
+	New    interface{}
+}
+
+// OneOfSwitch describes a "oneof" set whose selected member differs between
+// two Configs.
+type OneOfSwitch struct {
+	Name string
+	Old  Label
+	New  Label
+}
+
+// ConfigDiff is the result of comparing two Configs with Diff.
+type ConfigDiff struct {
+	// Changes is sorted by Label then Key.
+	Changes []*KeyChange
+
+	// Switches is sorted by Name.
+	Switches []*OneOfSwitch
+}
+
+// Empty returns true if the compared Configs are equivalent.
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Changes) == 0 && len(d.Switches) == 0
+}
+
+// ByFeature returns this ConfigDiff's Changes grouped by Feature label.
+func (d *ConfigDiff) ByFeature() map[Label][]*KeyChange {
+	m := make(map[Label][]*KeyChange)
+	for _, kc := range d.Changes {
+		m[kc.Label] = append(m[kc.Label], kc)
+	}
+	return m
+}
+
+// Diff compares the effective settings of two loaded Configs and returns
+// the keys that were added, removed or modified going from a to b -- along
+// with any "oneof" sets whose selected member has switched.
+//
+// Values are considered equal if they are deeply equal or if they have the
+// same string representation (e.g. the integer 80 from a config file and the
+// string "80" from an environment variable).
+func Diff(a, b *Config) *ConfigDiff {
+	d := new(ConfigDiff)
+
+	as := a.allValues()
+	bs := b.allValues()
+
+	for k, av := range as {
+		bv, ok := bs[k]
+		switch {
+		case !ok:
+			d.add(a, b, k, Removed, av, nil)
+		case !sameValue(av, bv):
+			d.add(a, b, k, Modified, av, bv)
+		}
+	}
+
+	for k, bv := range bs {
+		if _, ok := as[k]; !ok {
+			d.add(a, b, k, Added, nil, bv)
+		}
+	}
+
+	sort.Slice(d.Changes, func(i, j int) bool {
+		ci, cj := d.Changes[i], d.Changes[j]
+		if ci.Label != cj.Label {
+			return ci.Label < cj.Label
+		}
+		return ci.Key < cj.Key
+	})
+
+	names := make(map[string]bool)
+	for _, c := range []*Config{a, b} {
+		for name := range c.ooset {
+			names[name] = true
+		}
+	}
+
+	for name := range names {
+		if ao, bo := a.oomap[name], b.oomap[name]; ao != bo {
+			d.Switches = append(d.Switches, &OneOfSwitch{name, ao, bo})
+		}
+	}
+
+	sort.Slice(d.Switches, func(i, j int) bool { return d.Switches[i].Name < d.Switches[j].Name })
+
+	return d
+}
+
+func (d *ConfigDiff) add(a, b *Config, key string, ch Change, old, new interface{}) {
+	if a.isSecret(key) || b.isSecret(key) {
+		if old != nil {
+			old = secretMask
+		}
+		if new != nil {
+			new = secretMask
+		}
+	}
+
+	l := a.labelFor(key)
+	if l == "" {
+		l = b.labelFor(key)
+	}
+
+	d.Changes = append(d.Changes, &KeyChange{l, key, ch, old, new})
+}
+
+// allValues returns a flattened map of all current (unmasked) settings.
+func (c *Config) allValues() map[string]interface{} {
+	m := make(map[string]interface{})
+	for _, k := range c.AllKeys() {
+		m[k] = normalize(c.Get(k))
+	}
+	return m
+}
+
+// labelFor returns the label of the Feature owning key or the empty string
+// if key belongs to the base Feature (or no Feature at all).
+func (c *Config) labelFor(key string) Label {
+	var found Label
+
+	key = strings.ToLower(key)
+
+	for _, fd := range c.defs {
+		l := strings.ToLower(string(fd.label))
+		if l == "" || len(fd.label) <= len(found) {
+			continue
+		}
+
+		if key == l || strings.HasPrefix(key, l+".") {
+			found = fd.label
+		}
+	}
+
+	return found
+}
+
+func sameValue(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b) || fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
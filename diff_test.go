@@ -0,0 +1,69 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestDiff(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("db", func() Feature { return &secretFeature{User: "nobody"} })
+	RegisterOneOf("otf", "feata", func() Feature { return new(oneofFeatureA) })
+	RegisterOneOf("otf", "featb", func() Feature { return new(oneofFeatureB) })
+
+	load := func(text string) *Config {
+		c := New("difftest", FromReader("json", bytes.NewBufferString(text)))
+		if err := c.Load(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	a := load(`{ "db": { "password": "one" }, "feata": { "option": "x" } }`)
+	b := load(`{ "db": { "password": "two", "user": "nobody" }, "featb": { "option": "x" } }`)
+
+	want := &ConfigDiff{
+		Changes: []*KeyChange{
+			{"db", "db.password", Modified, secretMask, secretMask},
+			{"feata", "feata.option", Modified, "x", ""},
+			{"featb", "featb.option", Modified, "", "x"},
+		},
+		Switches: []*OneOfSwitch{{"otf", "feata", "featb"}},
+	}
+
+	got := Diff(a, b)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff(a, b) == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	if d := Diff(a, a); !d.Empty() {
+		t.Errorf("Diff(a, a) == %# v; Wanted empty diff", pretty.Formatter(d))
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DocFormat is the output format used by WriteDocs.
+type DocFormat int
+
+const (
+	Markdown DocFormat = iota
+	Manpage
+)
+
+// WriteDocs writes a reference document describing every config key known
+// to this Config -- along with its type, default value, flag, environment
+// variable, usage text and constraints -- and all OneOf groups. Output is
+// written to w in the given format.
+func (c *Config) WriteDocs(w io.Writer, format DocFormat) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	docs := c.featureDocs()
+
+	switch format {
+	case Markdown:
+		return c.writeMarkdown(w, docs)
+	case Manpage:
+		return c.writeManpage(w, docs)
+	default:
+		return fmt.Errorf("unknown doc format: %d", format)
+	}
+}
+
+type keyDoc struct {
+	key   string
+	typ   string
+	def   string
+	flag  string
+	env   string
+	usage string
+	notes []string
+}
+
+// desc returns this key's usage text followed by any constraint notes.
+func (kd *keyDoc) desc() string {
+	var parts []string
+	if kd.usage != "" {
+		parts = append(parts, kd.usage)
+	}
+
+	return strings.Join(append(parts, kd.notes...), "; ")
+}
+
+type featureDoc struct {
+	label Label
+	oneof string
+	keys  []*keyDoc
+}
+
+// featureDocs returns a featureDoc for the base Feature (if any) followed by
+// each registered Feature in label order.
+func (c *Config) featureDocs() []*featureDoc {
+	var fds []*featureDefn
+	if fd := c.defn(""); fd != nil {
+		fds = append(fds, fd)
+	}
+
+	for _, l := range c.features() {
+		fds = append(fds, c.defn(l))
+	}
+
+	var list []*featureDoc
+
+	for _, fd := range fds {
+		doc := &featureDoc{label: fd.label, oneof: fd.oneof}
+
+		for _, fi := range fd.fields {
+			kd := c.keyDoc(fd, fd.label.Key(fi.key), fd.fieldType(fi).String())
+
+			if len(fi.enum) > 0 {
+				kd.notes = append(kd.notes, "one of: "+strings.Join(fi.enum, ", "))
+			}
+
+			if fi.secret {
+				kd.def = ""
+				kd.notes = append(kd.notes, "secret")
+			}
+
+			if len(fi.aliases) > 0 {
+				al := make([]string, len(fi.aliases))
+				for i, a := range fi.aliases {
+					al[i] = fd.label.Key(a)
+				}
+				kd.notes = append(kd.notes, "deprecated aliases: "+strings.Join(al, ", "))
+			}
+
+			doc.keys = append(doc.keys, kd)
+		}
+
+		if fd.optional() {
+			doc.keys = append(doc.keys, c.keyDoc(fd, fd.label.Key(enabledKey), "bool"))
+		}
+
+		list = append(list, doc)
+	}
+
+	return list
+}
+
+func (c *Config) keyDoc(fd *featureDefn, key, typ string) *keyDoc {
+	kd := &keyDoc{
+		key: key,
+		typ: typ,
+		env: c.envName(strings.ToLower(key)),
+	}
+
+	if v, ok := fd.defaults[key]; ok && !isZero(v) {
+		kd.def = stringify(v)
+	}
+
+	for _, f := range fd.flags {
+		if k, ok := c.flagKey(f); ok && !f.Hidden && k == strings.ToLower(key) {
+			kd.flag = "--" + f.Name
+			kd.usage = f.Usage
+			break
+		}
+	}
+
+	return kd
+}
+
+//--------------------------------------
+
+func (c *Config) writeMarkdown(w io.Writer, docs []*featureDoc) error {
+	cell := func(s string, code bool) string {
+		if s == "" {
+			return ""
+		}
+		s = strings.Replace(s, "|", `\|`, -1)
+		if code {
+			return "`" + s + "`"
+		}
+		return s
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s Configuration Reference\n", c.name)
+
+	if groups := c.OneOfGroups(); len(groups) > 0 {
+		b.WriteString("\n## OneOf Groups\n\n")
+		b.WriteString("| Group | Members | Key | Flag | Env |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+
+		for _, g := range groups {
+			members := make([]string, len(g.Members))
+			for i, m := range g.Members {
+				members[i] = cell(string(m), true)
+			}
+
+			sk := selectorKey(g.Name)
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", cell(g.Name, true), strings.Join(members, ", "),
+				cell(sk, true), cell("--"+g.Name, true), cell(c.envName(sk), true))
+		}
+	}
+
+	for _, doc := range docs {
+		if doc.label == "" {
+			b.WriteString("\n## Base Settings\n")
+		} else {
+			fmt.Fprintf(&b, "\n## Feature `%s`\n", doc.label)
+		}
+
+		if doc.oneof != "" {
+			fmt.Fprintf(&b, "\nMember of OneOf group `%s`.\n", doc.oneof)
+		}
+
+		if len(doc.keys) == 0 {
+			b.WriteString("\nNo config keys.\n")
+			continue
+		}
+
+		b.WriteString("\n| Key | Type | Default | Flag | Env | Description |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+
+		for _, kd := range doc.keys {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", cell(kd.key, true), cell(kd.typ, true),
+				cell(kd.def, true), cell(kd.flag, true), cell(kd.env, true), cell(kd.desc(), false))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//--------------------------------------
+
+func (c *Config) writeManpage(w io.Writer, docs []*featureDoc) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ".TH %s 5 \"\" \"\" %s\n", troffQuote(strings.ToUpper(c.name)), troffQuote(c.name+" configuration"))
+	fmt.Fprintf(&b, ".SH NAME\n%s \\- configuration reference\n", troff(c.name))
+
+	b.WriteString(".SH DESCRIPTION\n")
+	b.WriteString("Each setting may be given in a config file, as an environment variable or as a command line flag.\n")
+	b.WriteString("Flags override environment variables which, in turn, override config files.\n")
+
+	if groups := c.OneOfGroups(); len(groups) > 0 {
+		b.WriteString(".SH ONEOF GROUPS\n")
+
+		for _, g := range groups {
+			members := make([]string, len(g.Members))
+			for i, m := range g.Members {
+				members[i] = troff(string(m))
+			}
+
+			sk := selectorKey(g.Name)
+			fmt.Fprintf(&b, ".TP\n.B %s\nOne of: %s.\n.br\nSelected by key \\fB%s\\fR, flag \\fB\\-\\-%s\\fR or env \\fB%s\\fR.\n",
+				troff(g.Name), strings.Join(members, ", "), troff(sk), troff(g.Name), troff(c.envName(sk)))
+		}
+	}
+
+	b.WriteString(".SH SETTINGS\n")
+
+	for _, doc := range docs {
+		title := "Base settings"
+		if doc.label != "" {
+			title = fmt.Sprintf("Feature %s", doc.label)
+		}
+		if doc.oneof != "" {
+			title += fmt.Sprintf(" (one of %s)", doc.oneof)
+		}
+
+		fmt.Fprintf(&b, ".SS %s\n", troffQuote(title))
+
+		for _, kd := range doc.keys {
+			fmt.Fprintf(&b, ".TP\n.B %s\n\\fI%s\\fR", troff(kd.key), troff(kd.typ))
+			if kd.def != "" {
+				fmt.Fprintf(&b, ", default: %s", troff(kd.def))
+			}
+			b.WriteString("\n.br\n")
+
+			if kd.flag != "" {
+				fmt.Fprintf(&b, "Flag: \\fB%s\\fR, ", troff(kd.flag))
+			}
+			fmt.Fprintf(&b, "Env: \\fB%s\\fR\n", troff(kd.env))
+
+			if d := kd.desc(); d != "" {
+				fmt.Fprintf(&b, ".br\n%s\n", troff(d))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// troff escapes s for use in troff text.
+func troff(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	s = strings.Replace(s, "-", `\-`, -1)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func troffQuote(s string) string {
+	return `"` + strings.Replace(troff(s), `"`, `\(dq`, -1) + `"`
+}
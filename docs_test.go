@@ -0,0 +1,75 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDocs(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("proc", func() Feature { return &enumFeature{Mode: "fast", Token: "xyzzy"} })
+	RegisterOneOf("store", "disk", func() Feature { return mkTestFeature() })
+
+	c := New("doctest")
+
+	tests := []struct {
+		format DocFormat
+		want   []string
+	}{
+		{Markdown, []string{
+			"# doctest Configuration Reference",
+			"| `store` | `disk` | `store.type` | `--store` | `DOCTEST_STORE_TYPE` |",
+			"## Feature `proc`",
+			"| `proc.mode` | `string` | `fast` | `--proc.mode` | `DOCTEST_PROC_MODE` | Processing mode; one of: fast, slow |",
+			"| `proc.levels` | `[]string` |  |  | `DOCTEST_PROC_LEVELS` | one of: low, high |",
+			"| `proc.token` | `string` |  |  | `DOCTEST_PROC_TOKEN` | secret |",
+			"Member of OneOf group `store`.",
+		}},
+		{Manpage, []string{
+			".TH \"DOCTEST\" 5",
+			".SH ONEOF GROUPS",
+			".B proc.mode\n\\fIstring\\fR, default: fast\n.br\nFlag: \\fB\\-\\-proc.mode\\fR, Env: \\fBDOCTEST_PROC_MODE\\fR\n",
+			".SS \"Feature disk (one of store)\"",
+		}},
+	}
+
+	for _, tc := range tests {
+		var buf bytes.Buffer
+		if err := c.WriteDocs(&buf, tc.format); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range tc.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("c.WriteDocs(w, %d) missing %q in:\n%s", tc.format, want, buf.String())
+			}
+		}
+	}
+
+	if err := c.WriteDocs(new(bytes.Buffer), DocFormat(99)); err == nil {
+		t.Errorf("c.WriteDocs(w, 99) == nil; Wanted error")
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"toolman.org/base/log/v2"
+)
+
+// Environment files (aka "dotenv" files) provide environment variables to a
+// Config without modifying the process environment. Variables are named the
+// same as those recognized through viper's AutomaticEnv (e.g. for the key
+// "feat.thing-one" with an env prefix of "app", "APP_FEAT_THING_ONE") and
+// are applied at the environment level -- i.e. they override config files
+// but are themselves overridden by actual environment variables and flags.
+//
+// The file format is:
+//
+//	# Comments and blank lines are ignored
+//	APP_FEAT_NAME=value            # Unquoted values end at a " #" comment
+//	export APP_FEAT_OTHER='single' # A leading "export " is ignored
+//	APP_FEAT_TEXT="double quoted values may contain \"escapes\"
+//	and span multiple lines"
+//
+// Single quoted values are taken literally while double quoted values may
+// contain the escapes \n, \r, \t, \", \\ and \$. Both may span lines.
+
+// readEnvFiles parses all environment files given by the EnvFile option or
+// the --env-file flag.
+func (c *Config) readEnvFiles() error {
+	c.dotenv = make(map[string]string)
+
+	files := append(append([]string(nil), c.opts.envFiles...), c.envFiles...)
+
+	for _, file := range files {
+		vars, err := readEnvFile(file)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Environment loaded from: %q", file)
+		c.report.Sources = append(c.report.Sources, file)
+
+		for k, v := range vars {
+			c.dotenv[k] = v
+		}
+	}
+
+	return nil
+}
+
+// applyEnvFiles sets the value for each known key (or alias) whose
+// environment variable is defined in an environment file -- unless that key
+// has already been given a value by an actual environment variable, a flag
+// or --set. Since viper offers no way to inject values at its environment
+// level, these are applied as overrides once all higher priority sources
+// are known.
+func (c *Config) applyEnvFiles() {
+	c.dotKeys = make(map[string]bool)
+
+	if len(c.dotenv) == 0 {
+		return
+	}
+
+	keys := make(map[string]string)
+
+	for _, k := range c.knownKeys() {
+		keys[k] = k
+	}
+
+	for _, a := range c.aliasNames() {
+		keys[strings.ToLower(a)] = strings.ToLower(c.alias[a])
+	}
+
+	for k, key := range keys {
+		name := c.envName(k)
+
+		v, ok := c.dotenv[name]
+		if !ok {
+			continue
+		}
+
+		if c.envSet(key) || c.flagChanged(key) || c.setKeys[key] {
+			continue
+		}
+
+		if k != key {
+			c.useAlias(k, key, originEnvFile)
+		}
+
+		c.Set(key, v)
+		c.dotKeys[key] = true
+	}
+}
+
+// envSet returns true if an actual environment variable provides a value
+// for key or any of its aliases.
+func (c *Config) envSet(key string) bool {
+	if _, ok := lookupEnv(c.envName(key)); ok {
+		return true
+	}
+
+	for a, ak := range c.alias {
+		if strings.ToLower(ak) != key {
+			continue
+		}
+		if _, ok := lookupEnv(c.envName(a)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readEnvFile(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars, err := parseEnvFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", file, err)
+	}
+
+	return vars, nil
+}
+
+func parseEnvFile(r io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+
+	var lineno int
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		lineno++
+		return scanner.Text(), true
+	}
+
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.Index(line, "=")
+		if eq < 1 {
+			return nil, fmt.Errorf("%d: invalid line: %q", lineno, line)
+		}
+
+		start := lineno
+		name := strings.TrimSpace(line[:eq])
+		val := strings.TrimLeft(line[eq+1:], " \t")
+
+		if val == "" || (val[0] != '"' && val[0] != '\'') {
+			if i := strings.Index(val, " #"); i >= 0 {
+				val = val[:i]
+			}
+			vars[name] = strings.TrimSpace(val)
+			continue
+		}
+
+		quote := val[0]
+		val = val[1:]
+
+		// Accumulate lines until we find the closing quote
+		var buf strings.Builder
+		for {
+			if end, ok := closingQuote(val, quote); ok {
+				buf.WriteString(val[:end])
+				if rest := strings.TrimSpace(val[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+					return nil, fmt.Errorf("%d: unexpected text after quoted value for %s: %q", lineno, name, rest)
+				}
+				break
+			}
+
+			buf.WriteString(val)
+			buf.WriteByte('\n')
+
+			if val, ok = next(); !ok {
+				return nil, fmt.Errorf("%d: unterminated quoted value for %s", start, name)
+			}
+		}
+
+		if quote == '"' {
+			vars[name] = unescapeEnv(buf.String())
+		} else {
+			vars[name] = buf.String()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// closingQuote returns the index of the first unescaped quote in s.
+func closingQuote(s string, quote byte) (int, bool) {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func unescapeEnv(s string) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			buf.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case '"', '\\', '$':
+			buf.WriteByte(s[i])
+		default:
+			buf.WriteByte('\\')
+			buf.WriteByte(s[i])
+		}
+	}
+
+	return buf.String()
+}
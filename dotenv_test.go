@@ -0,0 +1,134 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	in := strings.Join([]string{
+		"# comment",
+		"",
+		"PLAIN=value # comment",
+		"export EXPORTED = spaced ",
+		`SINGLE='back\slash' # comment`,
+		`DOUBLE="tab\there"`,
+		`HASH="a # b"`,
+		`MULTI="one`,
+		`two"`,
+		"EMPTY=",
+	}, "\n")
+
+	want := map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "spaced",
+		"SINGLE":   `back\slash`,
+		"DOUBLE":   "tab\there",
+		"HASH":     "a # b",
+		"MULTI":    "one\ntwo",
+		"EMPTY":    "",
+	}
+
+	got, err := parseEnvFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEnvFile(...) == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	for _, bad := range []string{"NOEQUALS", "=value", `OPEN="never closed`, `SINGLE='it\'s'`, `TRAIL="value" extra`} {
+		if _, err := parseEnvFile(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseEnvFile(%q) == nil; Wanted error", bad)
+		}
+	}
+}
+
+func TestEnvFile(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	os.Setenv("DOTENVTEST_FEAT_OTHER", "7")
+	defer os.Unsetenv("DOTENVTEST_FEAT_OTHER")
+
+	file := `{ "feat": { "thing-one": "from file", "other": 3 } }`
+
+	c := New("dotenvtest", FromReader("json", strings.NewReader(file)), EnvFile("testdata/test.env"))
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &testFeature{ThingOne: `from "env" file`, Other: 7, Stuff: "multi\nline"}
+
+	if got := c.Feature("feat").(*testFeature); got.ThingOne != want.ThingOne || got.Other != want.Other || got.Stuff != want.Stuff {
+		t.Errorf("c.Feature(%q) == %# v; Wanted %# v", "feat", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	for key, want := range map[string]string{"feat.thing-one": originEnvFile, "feat.other": originEnv, "feat.stuff": originEnvFile} {
+		if got := c.origin(key); got != want {
+			t.Errorf("c.origin(%q) == %q; Wanted %q", key, got, want)
+		}
+	}
+
+	if v, _ := lookupKey(c.raw, "feat.thing-one"); v != "from file" {
+		t.Errorf("raw value for %q == %v; Wanted %q", "feat.thing-one", v, "from file")
+	}
+
+	if _, ok := os.LookupEnv("DOTENVTEST_FEAT_THING_ONE"); ok {
+		t.Errorf("environment modified by env file")
+	}
+
+	// Flags take precedence over environment files
+	c = New("dotenvtest", IgnoreConfigFileErrors, EnvFile("testdata/test.env"))
+
+	if err := c.flags.Parse([]string{"--feat.thing-one", "from flag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.Feature("feat").(*testFeature).ThingOne; got != "from flag" {
+		t.Errorf("ThingOne == %q; Wanted %q", got, "from flag")
+	}
+
+	if got := c.origin("feat.thing-one"); got != originFlag {
+		t.Errorf("c.origin(%q) == %q; Wanted %q", "feat.thing-one", got, originFlag)
+	}
+
+	c = New("dotenvtest", IgnoreConfigFileErrors, EnvFile("testdata/missing.env"))
+
+	if err := c.Load(context.Background()); err == nil {
+		t.Errorf("c.Load() with missing env file == nil; Wanted error")
+	}
+}
@@ -20,13 +20,20 @@
 
 package basecfg
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
 
 type FeatureError error
 
 var (
 	ErrRegistrationClosed = FeatureError(errors.New("feature registration is closed"))
 	ErrMissingOneOfName   = FeatureError(errors.New("cannot register OneOf without a name"))
+	ErrInvalidMigration   = FeatureError(errors.New("migration must advance the config version"))
+	ErrDuplicateMigration = FeatureError(errors.New("duplicate migration for feature and version"))
 )
 
 type DuplicateLabelError struct {
@@ -53,3 +60,235 @@ func multipleOneOfError(name string, feat1, feat2 Label) *MultipleOneOfError {
 		error:    errors.New("multiple configurations found for mutually exclusive feature set"),
 	}
 }
+
+type UnknownOneOfError struct {
+	Name    string
+	Choice  Label
+	Choices []Label
+	error
+}
+
+func unknownOneOfError(name string, choice Label, choices []Label) *UnknownOneOfError {
+	return &UnknownOneOfError{
+		Name:    name,
+		Choice:  choice,
+		Choices: choices,
+		error:   fmt.Errorf("unknown selection %q for %q (valid choices: %s)", choice, name, joinLabels(choices)),
+	}
+}
+
+type UnknownKeysError struct {
+	Keys []*UnknownKey
+	error
+}
+
+func unknownKeysError(keys []*UnknownKey) *UnknownKeysError {
+	list := make([]string, len(keys))
+	for i, uk := range keys {
+		list[i] = uk.String()
+	}
+
+	return &UnknownKeysError{
+		Keys:  keys,
+		error: fmt.Errorf("unknown config keys: %s", strings.Join(list, "; ")),
+	}
+}
+
+type AliasConflictError struct {
+	Key    string
+	Alias  string
+	Source string
+	error
+}
+
+func aliasConflictError(key, alias, source string) *AliasConflictError {
+	return &AliasConflictError{
+		Key:    key,
+		Alias:  alias,
+		Source: source,
+		error:  fmt.Errorf("conflicting values for %q and its alias %q (from %s)", key, alias, source),
+	}
+}
+
+type MigrationError struct {
+	Label Label
+	From  int
+	To    int
+	error
+}
+
+func migrationError(l Label, from, to int, err error) *MigrationError {
+	return &MigrationError{
+		Label: l,
+		From:  from,
+		To:    to,
+		error: fmt.Errorf("migrating %q config from version %d to %d: %v", l, from, to, err),
+	}
+}
+
+type StartError struct {
+	Label Label
+	error
+}
+
+func startError(l Label, err error) *StartError {
+	return &StartError{l, fmt.Errorf("starting feature %q: %v", l, err)}
+}
+
+type CloseError struct {
+	Label Label
+	error
+}
+
+func closeError(l Label, err error) *CloseError {
+	return &CloseError{l, fmt.Errorf("closing feature %q: %v", l, err)}
+}
+
+type UnknownFeatureError struct {
+	Label Label
+	error
+}
+
+func unknownFeatureError(l Label) *UnknownFeatureError {
+	return &UnknownFeatureError{l, fmt.Errorf("unknown feature %q", l)}
+}
+
+type UnavailableFeatureError struct {
+	Label Label
+	error
+}
+
+func unavailableFeatureError(l Label, why string) *UnavailableFeatureError {
+	return &UnavailableFeatureError{l, fmt.Errorf("feature %q %s", l, why)}
+}
+
+type NoSelectionError struct {
+	Name    string
+	Choices []Label
+	error
+}
+
+func noSelectionError(name string, choices []Label) *NoSelectionError {
+	msg := fmt.Sprintf("no feature selected for %q", name)
+	if len(choices) == 0 {
+		msg = fmt.Sprintf("unknown oneof name %q", name)
+	}
+
+	return &NoSelectionError{name, choices, errors.New(msg)}
+}
+
+type FeatureTypeError struct {
+	Label Label
+	Want  reflect.Type
+	Got   reflect.Type
+	error
+}
+
+func featureTypeError(l Label, want, got reflect.Type) *FeatureTypeError {
+	return &FeatureTypeError{
+		Label: l,
+		Want:  want,
+		Got:   got,
+		error: fmt.Errorf("feature %q is of type %v; not assignable to %v", l, got, want),
+	}
+}
+
+type SourceError struct {
+	Source string
+	error
+}
+
+func sourceError(name string, err error) *SourceError {
+	return &SourceError{name, fmt.Errorf("reading config source %s: %v", name, err)}
+}
+
+type ConfigNotFoundError struct {
+	Name string
+	Dirs []string
+	Exts []string
+	error
+}
+
+func configNotFoundError(name string, dirs, exts []string) *ConfigNotFoundError {
+	return &ConfigNotFoundError{
+		Name:  name,
+		Dirs:  dirs,
+		Exts:  exts,
+		error: fmt.Errorf("config file %q not found in [%s] with extensions [%s]", name, strings.Join(dirs, ", "), strings.Join(exts, ", ")),
+	}
+}
+
+// ConfigParseError is returned when config data cannot be parsed. File is
+// empty for data that doesn't come from a file and Line is zero if it cannot
+// be determined.
+type ConfigParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ConfigParseError) Error() string {
+	var loc string
+	switch {
+	case e.File != "" && e.Line > 0:
+		loc = fmt.Sprintf("%s:%d: ", e.File, e.Line)
+	case e.File != "":
+		loc = e.File + ": "
+	case e.Line > 0:
+		loc = fmt.Sprintf("line %d: ", e.Line)
+	}
+
+	return fmt.Sprintf("parsing config: %s%v", loc, e.Err)
+}
+
+// SetError is returned for an invalid --set argument.
+type SetError struct {
+	Arg string
+	Key string
+	error
+}
+
+func setError(arg, key string, err error) *SetError {
+	return &SetError{arg, key, fmt.Errorf("invalid --set %q: %v", arg, err)}
+}
+
+type EnumError struct {
+	Key     string
+	Value   string
+	Choices []string
+	error
+}
+
+func enumError(key, value string, choices []string) *EnumError {
+	return &EnumError{
+		Key:     key,
+		Value:   value,
+		Choices: choices,
+		error:   fmt.Errorf("invalid value %q for %q (valid choices: %s)", value, key, strings.Join(choices, ", ")),
+	}
+}
+
+// FlagConflictError is returned by Load if the selector flag for a OneOf
+// group would have the same name as another flag.
+type FlagConflictError struct {
+	Name string
+	error
+}
+
+func flagConflictError(name string) *FlagConflictError {
+	return &FlagConflictError{name, fmt.Errorf("selector flag --%s for OneOf %q conflicts with an existing flag", name, name)}
+}
+
+// DecodeError is returned when a config value cannot be decoded into the
+// type of its target field. File and Line indicate where the value was set
+// if it came from a YAML or JSON config file. Value is masked for secret
+// fields.
+type DecodeError struct {
+	Label      Label
+	Key        string
+	Value      interface{}
+	TargetType reflect.Type
+	File       string
+	Line       int
+	error
+}
@@ -33,6 +33,18 @@ type Feature interface {
 	Validate(context.Context) error
 }
 
+// Optional may be implemented by a non-base Feature that can be switched off
+// using the conventional "enabled" key (e.g. "feat.enabled: false"); a flag
+// for this key (e.g. "--feat.enabled") is added automatically. The value of
+// this key defaults to the result of EnabledByDefault.
+//
+// Disabled Features are not validated and Config.Feature returns nil for
+// them.
+type Optional interface {
+	Feature
+	EnabledByDefault() bool
+}
+
 // FeatureFunc is a function that returns a newly created Feature and should be
 // the second argument to `Register()`.
 type FeatureFunc func() Feature
@@ -68,6 +80,12 @@ func Register(l Label, f FeatureFunc) error {
 // with the same `oneOf` value, only one may be configured at runtime. If more
 // than one of these Features is configured, validation will fail.
 //
+// Alternatively, the active member may be selected explicitly by setting the
+// "<oneOf>.type" config key to its label -- either in a config file, with the
+// generated "--<oneOf>" flag or its corresponding environment variable. When
+// an explicit selection is made, configuration for all other members is
+// ignored.
+//
 // If the value fo `oneOf` is the empty string, RegisterOneOf will return
 // ErrMissingOneOfName -- otherwise, returned errors are as described for
 // Register.
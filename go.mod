@@ -7,6 +7,7 @@ require (
 	github.com/mitchellh/mapstructure v1.1.2
 	github.com/spf13/pflag v1.0.3
 	github.com/spf13/viper v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
 	toolman.org/base/log/v2 v2.1.0
 	toolman.org/base/toolman/v2 v2.1.1
 )
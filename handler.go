@@ -0,0 +1,90 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+
+	"toolman.org/base/log/v2"
+)
+
+// effectiveConfig is the JSON document served by Config.Handler.
+type effectiveConfig struct {
+	File     string                 `json:"file,omitempty"`
+	OneOfs   map[string]Label       `json:"oneofs,omitempty"`
+	Settings map[string]interface{} `json:"settings"`
+	Sources  map[string]string      `json:"sources"`
+}
+
+func (c *Config) effective() *effectiveConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ec := &effectiveConfig{
+		File:     c.ConfigFileUsed(),
+		OneOfs:   make(map[string]Label),
+		Settings: c.settings(),
+		Sources:  make(map[string]string),
+	}
+
+	for name, l := range c.oomap {
+		ec.OneOfs[name] = l
+	}
+
+	for k := range ec.Settings {
+		ec.Sources[k] = c.origin(k)
+	}
+
+	return ec
+}
+
+// Handler returns an http.Handler that serves the current, effective
+// configuration as a JSON document. Along with each setting (keyed by its
+// fully qualified config key), the document indicates where that setting's
+// value came from (e.g. "flag", "env", "file", "source" or "default"), the
+// selected member of each "oneof" set, and the config file used (if any).
+//
+// The values of all fields tagged as `secret` are masked.
+//
+// This handler is typically installed at "/debug/config".
+func (c *Config) Handler() http.Handler {
+	return http.HandlerFunc(c.serveHTTP)
+}
+
+func (c *Config) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(c.effective()); err != nil {
+		log.Warningf("Error encoding effective config: %v", err)
+	}
+}
+
+// Publish exports the effective configuration (as served by Handler) as an
+// expvar variable with the given name. As with expvar.Publish, Publish panics
+// if name is already in use.
+func (c *Config) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return c.effective() }))
+}
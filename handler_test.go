@@ -0,0 +1,83 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+	"github.com/spf13/pflag"
+)
+
+type secretFeature struct {
+	User     string `cfg:"user"`
+	Password string `cfg:"password,secret"`
+}
+
+func (sf *secretFeature) FlagSet(*pflag.FlagSet)         {}
+func (sf *secretFeature) Validate(context.Context) error { return nil }
+
+func TestHandler(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("db", func() Feature { return &secretFeature{User: "nobody"} })
+
+	os.Setenv("HANDLERTEST_DB_USER", "admin")
+	defer os.Unsetenv("HANDLERTEST_DB_USER")
+
+	buf := bytes.NewBufferString(`{ "db": { "password": "hunter2" } }`)
+
+	c := New("handlertest", FromReader("json", buf))
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+
+	got := new(effectiveConfig)
+	if err := json.NewDecoder(rec.Body).Decode(got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &effectiveConfig{
+		Settings: map[string]interface{}{
+			"db.user":     "admin",
+			"db.password": secretMask,
+		},
+		Sources: map[string]string{
+			"db.user":     originEnv,
+			"db.password": originSource,
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Handler() served %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// helpFeatureFlag is the name of the flag used to show usage for only
+// specific Features.
+const helpFeatureFlag = "help-feature"
+
+// exit is called to terminate the program; it is a variable so tests may
+// replace it.
+var exit = os.Exit
+
+// usage is installed as the flag usage function for this Config's flags
+// (and, by InstallUsage, for pflag.CommandLine).
+func (c *Config) usage() {
+	c.Usage(os.Stderr)
+}
+
+// InstallUsage replaces the global pflag.Usage function (used when parsing
+// pflag.CommandLine fails or --help is given) with one that writes this
+// Config's Usage message. New does not do this on its own.
+func (c *Config) InstallUsage() {
+	pflag.Usage = c.usage
+}
+
+// helpFeatures writes usage for the Features named by --help-feature and
+// then exits the program.
+func (c *Config) helpFeatures() {
+	labels := make([]Label, len(c.helpFeats))
+	for i, l := range c.helpFeats {
+		labels[i] = Label(l)
+	}
+
+	if err := c.Usage(os.Stderr, labels...); err != nil {
+		fmt.Fprintf(os.Stderr, "--%s: %v\n", helpFeatureFlag, err)
+		exit(2)
+		return
+	}
+
+	exit(0)
+}
+
+type usageSection struct {
+	title string
+	flags []*pflag.Flag
+}
+
+// Usage writes a usage message for this Config's flags to w. Flags are
+// grouped into sections by Feature and, for each flag associated with a
+// config key, the key and its environment variable are also shown. If any
+// labels are given, only the sections for those Features are written.
+//
+// If the EffectiveHelp option was given, each flag's effective value (and
+// its origin) is shown in place of its default.
+func (c *Config) Usage(w io.Writer, labels ...Label) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, l := range labels {
+		if c.defn(l) == nil {
+			return unknownFeatureError(l)
+		}
+	}
+
+	fmt.Fprintf(w, "Usage of %s:\n", os.Args[0])
+
+	// Effective values for an unloaded Config are taken from a (loaded) fork.
+	src := c
+	eff := c.opts.effHelp
+	if eff && !c.loaded {
+		src = c.fork()
+		if _, err := src.loadSettings(context.Background()); err != nil {
+			fmt.Fprintf(w, "  (showing defaults; config could not be loaded: %v)\n", err)
+			eff = false
+		}
+	}
+
+	if eff && len(src.report.Sources) > 0 {
+		fmt.Fprintf(w, "  (effective values loaded from: %s)\n", strings.Join(src.report.Sources, ", "))
+	}
+
+	for _, s := range src.usageSections(labels) {
+		fmt.Fprintf(w, "\n%s:\n", s.title)
+		src.printFlags(w, s.flags, eff)
+	}
+
+	return nil
+}
+
+// usageSections returns the flags for each of the given Features (or all
+// flags if no labels are given) grouped into sections.
+func (c *Config) usageSections(labels []Label) []*usageSection {
+	owned := make(map[*pflag.Flag]bool)
+	for _, fd := range c.defs {
+		for _, f := range fd.flags {
+			owned[f] = true
+		}
+	}
+
+	var list []*usageSection
+
+	all := len(labels) == 0
+	if all {
+		general := &usageSection{title: "General options"}
+
+		c.flags.VisitAll(func(f *pflag.Flag) {
+			if !owned[f] {
+				general.flags = append(general.flags, f)
+			}
+		})
+
+		if fd := c.defn(""); fd != nil {
+			general.flags = append(general.flags, fd.flags...)
+		}
+
+		list = append(list, general)
+
+		for _, l := range c.features() {
+			labels = append(labels, l)
+		}
+	}
+
+	for _, l := range labels {
+		fd := c.defn(l)
+		if l == "" || len(fd.flags) == 0 {
+			continue
+		}
+
+		title := fmt.Sprintf("Feature %q", l)
+		if fd.oneof != "" {
+			title += fmt.Sprintf(" (one of %q; select with --%s)", fd.oneof, fd.oneof)
+		}
+
+		list = append(list, &usageSection{title, fd.flags})
+	}
+
+	// Include any flags added to the global flag set by other packages.
+	if all {
+		other := &usageSection{title: "Other options"}
+
+		pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
+			if c.flags.Lookup(f.Name) == nil {
+				other.flags = append(other.flags, f)
+			}
+		})
+
+		if len(other.flags) > 0 {
+			list = append(list, other)
+		}
+	}
+
+	return list
+}
+
+// printFlags writes a usage line for each (non-hidden) flag to w followed,
+// for flags associated with a config key, by a line showing the key and its
+// environment variable. If eff is true, the effective value of each key is
+// shown along with its origin; otherwise, only default values are shown.
+func (c *Config) printFlags(w io.Writer, flags []*pflag.Flag, eff bool) {
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+
+	for _, f := range flags {
+		if f.Hidden {
+			continue
+		}
+
+		fmt.Fprintln(tw, c.flagLine(f, eff))
+
+		if key, ok := c.flagKey(f); ok {
+			fmt.Fprintf(tw, "\t  key: %s, env: %s\n", key, c.envName(key))
+		}
+	}
+
+	tw.Flush()
+}
+
+func (c *Config) flagLine(f *pflag.Flag, eff bool) string {
+	varname, usage := pflag.UnquoteUsage(f)
+
+	line := "      --" + f.Name
+	if f.Shorthand != "" && f.ShorthandDeprecated == "" {
+		line = fmt.Sprintf("  -%s, --%s", f.Shorthand, f.Name)
+	}
+
+	if varname != "" {
+		line += " " + varname
+	}
+
+	if f.NoOptDefVal != "" && f.Value.Type() != "bool" {
+		line += fmt.Sprintf("[=%s]", f.NoOptDefVal)
+	}
+
+	line += "\t" + usage
+
+	if key, ok := c.flagKey(f); ok && eff {
+		if o := c.origin(key); o != originDefault {
+			return line + fmt.Sprintf(" (%s: %s)", o, c.effectiveValue(f, key))
+		}
+	}
+
+	if !zeroDefault(f) {
+		line += fmt.Sprintf(" (default %s)", quoteValue(f, f.DefValue))
+	}
+
+	return line
+}
+
+// flagKey returns the config key associated with flag f, if any.
+func (c *Config) flagKey(f *pflag.Flag) (string, bool) {
+	if sk, ok := c.sels[f.Name]; ok {
+		return sk, true
+	}
+
+	if c.flags.Lookup(f.Name) != f {
+		return "", false
+	}
+
+	key := strings.ToLower(f.Name)
+	if c.keyType(key) == nil {
+		return "", false
+	}
+
+	return key, true
+}
+
+// effectiveValue returns the current value for key, formatted for flag f.
+func (c *Config) effectiveValue(f *pflag.Flag, key string) string {
+	if c.isSecret(key) {
+		return secretMask
+	}
+
+	return quoteValue(f, stringify(c.Get(key)))
+}
+
+func quoteValue(f *pflag.Flag, v string) string {
+	if f.Value.Type() == "string" {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
+
+// zeroDefault mimics pflag's test for whether a flag's default value is
+// worth displaying.
+func zeroDefault(f *pflag.Flag) bool {
+	switch f.DefValue {
+	case "", "0", "0s", "false", "[]", "<nil>":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestUsage(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+	RegisterOneOf("store", "disk", func() Feature { return mkTestFeature() })
+
+	c := New("helptest")
+
+	var buf bytes.Buffer
+	if err := c.Usage(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"General options:\n",
+		"--config-file string",
+		`--store string`,
+		"key: store.type, env: HELPTEST_STORE_TYPE",
+		"Feature \"feat\":\n",
+		`Thing number one (default "foo")`,
+		"key: feat.thing-one, env: HELPTEST_FEAT_THING_ONE",
+		`Feature "disk" (one of "store"; select with --store):`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("c.Usage(...) missing %q in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := c.Usage(&buf, "disk"); err != nil {
+		t.Fatal(err)
+	}
+	out = buf.String()
+
+	if !strings.Contains(out, "--disk.thing-one") || strings.Contains(out, "--feat.thing-one") || strings.Contains(out, "General options") {
+		t.Errorf("c.Usage(w, %q) == \n%s\nWanted only section for %q", "disk", out, "disk")
+	}
+
+	if err := c.Usage(&buf, "nope"); err == nil {
+		t.Errorf("c.Usage(w, %q) == nil; Wanted %T", "nope", &UnknownFeatureError{})
+	}
+}
+
+func TestInstallUsage(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	defer func(f func()) { pflag.Usage = f }(pflag.Usage)
+
+	var called bool
+	orig := func() { called = true }
+	pflag.Usage = orig
+
+	c := New("helptest")
+
+	if pflag.Usage(); !called {
+		t.Errorf("New() replaced pflag.Usage; Wanted it untouched")
+	}
+
+	c.InstallUsage()
+
+	if reflect.ValueOf(pflag.Usage).Pointer() == reflect.ValueOf(orig).Pointer() {
+		t.Errorf("c.InstallUsage() did not replace pflag.Usage")
+	}
+}
+
+func TestHelpFeature(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	defer func(f func(int)) { exit = f }(exit)
+
+	var code int
+	exit = func(c int) { code = c }
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	for args, want := range map[string]int{"--help-feature=feat": 0, "--help-feature=nope": 2} {
+		c := New("helptest", IgnoreConfigFileErrors)
+		if err := c.flags.Parse([]string{args}); err != nil {
+			t.Fatal(err)
+		}
+
+		code = -1
+		c.helpFeatures()
+
+		if code != want {
+			t.Errorf("%s: exit(%d); Wanted exit(%d)", args, code, want)
+		}
+	}
+}
+
+func TestEffectiveUsage(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	c := New("helptest", EffectiveHelp, FromReader("json", strings.NewReader(`{ "feat": { "thing-one": "file" } }`)))
+
+	var buf bytes.Buffer
+	if err := c.Usage(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"(effective values loaded from: reader:json)",
+		`Thing number one (source: "file")`,
+		`--config-file string`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("c.Usage(...) missing %q in:\n%s", want, out)
+		}
+	}
+
+	if c.loaded || c.raw != nil {
+		t.Errorf("c.Usage(...) left Config loaded")
+	}
+}
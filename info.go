@@ -0,0 +1,100 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import "reflect"
+
+// FeatureInfo describes a single Feature known to a Config. The base Feature,
+// if any, has an empty Label.
+type FeatureInfo struct {
+	Label Label
+	OneOf string
+	Type  reflect.Type
+
+	// Configured is true if config values were found for this Feature.
+	Configured bool
+
+	// Selected is true if this is the active member of its "oneof" set.
+	Selected bool
+
+	// Enabled is false if this is an Optional Feature that has been disabled.
+	Enabled bool
+
+	// Keys holds the sorted, fully qualified config keys for this Feature.
+	Keys []string
+
+	// Validated is true if this Feature's Validate method has been called
+	// while Err holds the result of that call.
+	Validated bool
+	Err       error
+}
+
+// FeatureInfos returns a FeatureInfo for each Feature known to this Config
+// (including its base Feature) sorted by label.
+func (c *Config) FeatureInfos() []*FeatureInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := make([]*FeatureInfo, len(c.defs))
+
+	for i, fd := range c.defs {
+		list[i] = &FeatureInfo{
+			Label:      fd.label,
+			OneOf:      fd.oneof,
+			Type:       reflect.TypeOf(fd.Feature),
+			Configured: fd.configured,
+			Selected:   fd.oneof != "" && c.oomap[fd.oneof] == fd.label,
+			Enabled:    !fd.disabled,
+			Keys:       fd.keys(),
+			Validated:  fd.validated,
+			Err:        fd.verr,
+		}
+	}
+
+	return list
+}
+
+// OneOfGroup describes a "oneof" set of mutually exclusive Features.
+type OneOfGroup struct {
+	Name     string
+	Members  []Label
+	Selected Label
+}
+
+// OneOfGroups returns a OneOfGroup for each "oneof" set known to this Config
+// sorted by name.
+func (c *Config) OneOfGroups() []*OneOfGroup {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := c.oneOfNames()
+	list := make([]*OneOfGroup, len(names))
+
+	for i, name := range names {
+		list[i] = &OneOfGroup{
+			Name:     name,
+			Members:  append([]Label(nil), c.ooset[name]...),
+			Selected: c.oomap[name],
+		}
+	}
+
+	return list
+}
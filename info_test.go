@@ -0,0 +1,66 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestFeatureInfos(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+	RegisterOneOf("otf", "feata", func() Feature { return new(oneofFeatureA) })
+	RegisterOneOf("otf", "featb", func() Feature { return new(oneofFeatureB) })
+
+	buf := bytes.NewBufferString(`{ "featb": { "option": "value1" } }`)
+
+	c := New("infotest", Base(mkTestFeature()), FromReader("json", buf))
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tfType := reflect.TypeOf(&testFeature{})
+
+	want := []*FeatureInfo{
+		{"", "", tfType, false, false, true, []string{"other", "stuff", "thing-one"}, true, nil},
+		{"feat", "", tfType, false, false, true, []string{"feat.other", "feat.stuff", "feat.thing-one"}, true, nil},
+		{"feata", "otf", reflect.TypeOf(&oneofFeatureA{}), false, false, true, []string{"feata.option"}, false, nil},
+		{"featb", "otf", reflect.TypeOf(&oneofFeatureB{}), true, true, true, []string{"featb.option"}, true, nil},
+	}
+
+	if got := c.FeatureInfos(); !reflect.DeepEqual(got, want) {
+		t.Errorf("c.FeatureInfos() == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	wantGroups := []*OneOfGroup{{"otf", []Label{"feata", "featb"}, "featb"}}
+
+	if got := c.OneOfGroups(); !reflect.DeepEqual(got, wantGroups) {
+		t.Errorf("c.OneOfGroups() == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(wantGroups))
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// k8sDataLink is the symlink Kubernetes uses when mounting ConfigMaps and
+// Secrets. It points to a timestamped directory holding the current set of
+// files and is atomically replaced whenever that set changes.
+const k8sDataLink = "..data"
+
+// KeyPerFile is a Source that reads a directory in which each file name is a
+// config key and its contents is that key's value -- the layout used by
+// Kubernetes when mounting a ConfigMap or Secret as a volume. Nested keys
+// may be provided either as subdirectories or with dotted file names (e.g.
+// "feat/port" or "feat.port"). A single trailing newline is trimmed from each
+// value. Files and directories whose names begin with "." are ignored.
+//
+// If the directory contains a "..data" symlink, all files are read from its
+// target so that a consistent set of values is seen even if the symlink is
+// swapped while being read.
+type KeyPerFile struct {
+	basicSource
+}
+
+// KeyPerFileSource returns a KeyPerFile Source for the given directory.
+func KeyPerFileSource(dir string) *KeyPerFile {
+	return &KeyPerFile{basicSource{dir}}
+}
+
+// Settings implements Source.
+func (kf *KeyPerFile) Settings(context.Context) (map[string]interface{}, error) {
+	root, err := kf.root()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+
+	err = walkKeyPerFile(root, "", func(key string, data []byte) {
+		m := make(map[string]interface{})
+		setKey(m, key, strings.TrimSuffix(string(data), "\n"))
+		mergeSettings(raw, m)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// Revision returns a string that changes whenever the directory's contents
+// change and may be used to detect when settings should be reloaded. For a
+// directory having a "..data" symlink, this is the symlink's target;
+// otherwise, it is a hash of all key names and values.
+func (kf *KeyPerFile) Revision() (string, error) {
+	if target, err := os.Readlink(filepath.Join(kf.name, k8sDataLink)); err == nil {
+		return target, nil
+	}
+
+	h := fnv.New64a()
+
+	err := walkKeyPerFile(kf.name, "", func(key string, data []byte) {
+		fmt.Fprintf(h, "%s=%d:", key, len(data))
+		h.Write(data)
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum64()), nil
+}
+
+// root returns the directory from which files should actually be read.
+func (kf *KeyPerFile) root() (string, error) {
+	link := filepath.Join(kf.name, k8sDataLink)
+
+	if _, err := os.Lstat(link); err != nil {
+		return kf.name, nil
+	}
+
+	return filepath.EvalSymlinks(link)
+}
+
+func walkKeyPerFile(dir, prefix string, fn func(key string, data []byte)) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range infos {
+		name := fi.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+
+		// Follow symlinks to determine whether this is a file or directory.
+		st, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if st.IsDir() {
+			if err := walkKeyPerFile(path, prefix+name+".", fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		fn(prefix+name, data)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestKeyPerFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "basecfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Mimic the layout used by Kubernetes for a mounted ConfigMap
+	mkVersion := func(version string, files map[string]string) {
+		vdir := filepath.Join(dir, version)
+		for name, val := range files {
+			path := filepath.Join(vdir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, []byte(val), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tmp := filepath.Join(dir, "..data_tmp")
+		if err := os.Symlink(version, tmp); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, k8sDataLink)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mkVersion("..v1", map[string]string{"feat.thing-one": "one\n", "feat/other": "12"})
+
+	for _, name := range []string{"feat.thing-one", "feat"} {
+		if err := os.Symlink(filepath.Join(k8sDataLink, name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := KeyPerFileSource(dir)
+	ctx := context.Background()
+
+	rev1, err := src.Revision()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := src.Settings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"feat": map[string]interface{}{"thing-one": "one", "other": "12"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("src.Settings() == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	mkVersion("..v2", map[string]string{"feat.thing-one": "two\n", "feat/other": "13"})
+
+	if rev2, err := src.Revision(); err != nil || rev2 == rev1 {
+		t.Errorf("src.Revision() == (%q, %v); Wanted (!%q, %v)", rev2, err, rev1, nil)
+	}
+
+	if got, err = src.Settings(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want = map[string]interface{}{
+		"feat": map[string]interface{}{"thing-one": "two", "other": "13"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("src.Settings() == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+}
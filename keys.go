@@ -0,0 +1,254 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"toolman.org/base/log/v2"
+)
+
+// UnknownKey describes a config key (or environment variable) that doesn't
+// map to any field of a registered Feature. Source is one of "file", "source",
+// "default-config", "env" or "env-file".
+type UnknownKey struct {
+	Key         string
+	Source      string
+	Suggestions []string
+}
+
+func (uk *UnknownKey) String() string {
+	s := fmt.Sprintf("%q from %s", uk.Key, uk.Source)
+	if len(uk.Suggestions) > 0 {
+		q := make([]string, len(uk.Suggestions))
+		for i, sg := range uk.Suggestions {
+			q[i] = fmt.Sprintf("%q", sg)
+		}
+		s += fmt.Sprintf(" (did you mean %s?)", strings.Join(q, " or "))
+	}
+	return s
+}
+
+// knownKeys returns the lower-cased, fully qualified config keys for all
+// Features known to this Config along with any other keys it recognizes.
+func (c *Config) knownKeys() []string {
+	var list []string
+
+	for _, fd := range c.defs {
+		for _, k := range fd.keys() {
+			list = append(list, strings.ToLower(k))
+		}
+
+		if fd.optional() {
+			list = append(list, strings.ToLower(fd.label.Key(enabledKey)))
+		}
+	}
+
+	for _, sk := range c.sels {
+		list = append(list, strings.ToLower(sk))
+	}
+
+	for a := range c.alias {
+		list = append(list, strings.ToLower(a))
+	}
+
+	list = append(list, versionKey)
+
+	sort.Strings(list)
+
+	return list
+}
+
+// unknownKeys returns an UnknownKey for each key in the config file or
+// DefaultConfig data (or each prefixed environment variable) that isn't
+// recognized by this Config.
+func (c *Config) unknownKeys() []*UnknownKey {
+	known := c.knownKeys()
+
+	isKnown := func(key string) bool {
+		for _, k := range known {
+			if key == k || strings.HasPrefix(key, k+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var list []*UnknownKey
+
+	for _, k := range flattenKeys(c.raw, "") {
+		if !isKnown(k) {
+			list = append(list, &UnknownKey{k, c.rawOrigin(k), suggest(k, known)})
+		}
+	}
+
+	for _, k := range flattenKeys(c.bundle, "") {
+		if !isKnown(k) {
+			list = append(list, &UnknownKey{k, originBundle, suggest(k, known)})
+		}
+	}
+
+	// Environment variables are only checked if they share a distinct prefix.
+	pfx := c.opts.envPrefix
+	if pfx == "" {
+		return list
+	}
+
+	pfx = strings.ToUpper(pfx) + "_"
+
+	envs := make(map[string]bool)
+	for _, k := range known {
+		envs[c.envName(k)] = true
+	}
+
+	var envList []string
+	for e := range envs {
+		envList = append(envList, e)
+	}
+
+	sort.Strings(envList)
+
+	for _, kv := range os.Environ() {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if strings.HasPrefix(name, pfx) && !envs[name] {
+			list = append(list, &UnknownKey{name, originEnv, suggest(name, envList)})
+		}
+	}
+
+	for name := range c.dotenv {
+		if _, ok := lookupEnv(name); !ok && strings.HasPrefix(name, pfx) && !envs[name] {
+			list = append(list, &UnknownKey{name, originEnvFile, suggest(name, envList)})
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+
+	return list
+}
+
+func (c *Config) checkKeys() error {
+	if c.opts.keys == AllowUnknownKeys {
+		return nil
+	}
+
+	unknown := c.unknownKeys()
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	if c.opts.keys == StrictKeys {
+		return unknownKeysError(unknown)
+	}
+
+	for _, uk := range unknown {
+		log.Warningf("Unknown config key: %s", uk)
+	}
+
+	return nil
+}
+
+// flattenKeys returns the dot separated keys for all leaf values in m.
+func flattenKeys(m map[string]interface{}, prefix string) []string {
+	var list []string
+
+	for k, v := range m {
+		key := prefix + strings.ToLower(k)
+		if sub, ok := normalize(v).(map[string]interface{}); ok && len(sub) > 0 {
+			list = append(list, flattenKeys(sub, key+".")...)
+		} else {
+			list = append(list, key)
+		}
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+// suggest returns up to three candidates that are within a reasonable edit
+// distance of key, closest first.
+func suggest(key string, candidates []string) []string {
+	type match struct {
+		s string
+		d int
+	}
+
+	limit := len(key) / 4
+	switch {
+	case limit < 1:
+		limit = 1
+	case limit > 3:
+		limit = 3
+	}
+
+	var matches []match
+	for _, c := range candidates {
+		if d := editDistance(key, c); d <= limit {
+			matches = append(matches, match{c, d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].d < matches[j].d })
+
+	var list []string
+	for i := 0; i < len(matches) && i < 3; i++ {
+		list = append(list, matches[i].s)
+	}
+
+	return list
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestStrictKeys(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	os.Setenv("STRICTTEST_FEAT_OTHR", "5")
+	defer os.Unsetenv("STRICTTEST_FEAT_OTHR")
+
+	text := `{ "feat": { "thing_one": "x", "other": 3 } }`
+
+	want := unknownKeysError([]*UnknownKey{
+		{"STRICTTEST_FEAT_OTHR", originEnv, []string{"STRICTTEST_FEAT_OTHER"}},
+		{"feat.thing_one", originSource, []string{"feat.thing-one"}},
+	})
+
+	c := New("stricttest", StrictKeys, FromReader("json", bytes.NewBufferString(text)))
+
+	if err := c.Load(context.Background()); !reflect.DeepEqual(err, want) {
+		t.Errorf("c.Load() == %# v; Wanted %# v", pretty.Formatter(err), pretty.Formatter(want))
+	}
+
+	c = New("stricttest", WarnOnUnknownKeys, FromReader("json", bytes.NewBufferString(text)))
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Errorf("c.Load() == %v; Wanted %v", err, nil)
+	}
+}
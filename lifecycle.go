@@ -0,0 +1,118 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"io"
+
+	"toolman.org/base/log/v2"
+)
+
+// Starter may be implemented by a Feature needing to perform some action
+// (e.g. open a connection pool or a listener) once its configuration has been
+// successfully loaded. Start is called by Load, after all Features have been
+// validated, for each enabled (and selected) Feature in label order (with the
+// base Feature first).
+//
+// If a Feature's Start method returns an error, all previously started
+// Features are closed (in reverse order) and Load returns a StartError.
+type Starter interface {
+	Start(context.Context) error
+}
+
+// ContextCloser may be implemented by a Feature that needs a Context when
+// being closed. Features may instead implement io.Closer.
+type ContextCloser interface {
+	Close(context.Context) error
+}
+
+// start calls Start for each active Feature implementing Starter. All active
+// Features are recorded (once all have been started) so they may later be
+// closed.
+func (c *Config) start(ctx context.Context) error {
+	var started []*featureDefn
+
+	for _, fd := range c.defs {
+		if c.inactive(fd) != "" {
+			continue
+		}
+
+		if s, ok := fd.Feature.(Starter); ok {
+			if err := s.Start(ctx); err != nil {
+				if cerr := closeFeatures(ctx, started); cerr != nil {
+					log.Warningf("Error closing features after failed start: %v", cerr)
+				}
+				return startError(fd.label, err)
+			}
+		}
+
+		started = append(started, fd)
+	}
+
+	c.mu.Lock()
+	c.active = started
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Close closes each Feature started by Load (i.e. all enabled and selected
+// Features implementing either io.Closer or ContextCloser) in the reverse of
+// the order in which they were started. All Features are closed regardless of
+// errors; the first error encountered is returned and the others are logged.
+func (c *Config) Close(ctx context.Context) error {
+	c.mu.Lock()
+	list := c.active
+	c.active = nil
+	c.mu.Unlock()
+
+	return closeFeatures(ctx, list)
+}
+
+// closeFeatures closes each of the given Features in reverse order.
+func closeFeatures(ctx context.Context, list []*featureDefn) error {
+	var first error
+
+	for i := len(list) - 1; i >= 0; i-- {
+		fd := list[i]
+
+		var err error
+		switch f := fd.Feature.(type) {
+		case ContextCloser:
+			err = f.Close(ctx)
+		case io.Closer:
+			err = f.Close()
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if first == nil {
+			first = closeError(fd.label, err)
+		} else {
+			log.Warningf("Error closing feature %q: %v", fd.label, err)
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type lifecycleFeature struct {
+	name   string
+	fail   bool
+	events *[]string
+}
+
+func (lf *lifecycleFeature) FlagSet(*pflag.FlagSet)         {}
+func (lf *lifecycleFeature) Validate(context.Context) error { return nil }
+
+func (lf *lifecycleFeature) Start(context.Context) error {
+	if lf.fail {
+		return errors.New("boom")
+	}
+	*lf.events = append(*lf.events, "start:"+lf.name)
+	return nil
+}
+
+func (lf *lifecycleFeature) Close() error {
+	*lf.events = append(*lf.events, "close:"+lf.name)
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	tests := []struct {
+		fail string
+		want []string
+		err  error
+	}{
+		{"", []string{"start:a", "start:b", "start:c", "close:c", "close:b", "close:a"}, nil},
+		{"c", []string{"start:a", "start:b", "close:b", "close:a"}, startError("c", errors.New("boom"))},
+	}
+
+	for _, tc := range tests {
+		reset := useTestRegistry()
+
+		var events []string
+		for _, n := range []string{"a", "b", "c"} {
+			lf := &lifecycleFeature{name: n, fail: n == tc.fail, events: &events}
+			Register(Label(n), func() Feature { return lf })
+		}
+
+		c := New("lifecycletest", IgnoreConfigFileErrors)
+
+		ctx := context.Background()
+
+		if err := c.Load(ctx); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("c.Load() == %v; Wanted %v", err, tc.err)
+		}
+
+		if err := c.Close(ctx); err != nil {
+			t.Errorf("c.Close() == %v; Wanted %v", err, nil)
+		}
+
+		if !reflect.DeepEqual(events, tc.want) {
+			t.Errorf("events == %q; Wanted %q", events, tc.want)
+		}
+
+		reset()
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"errors"
+	"reflect"
+)
+
+var errBadTarget = errors.New("target must be a non-nil pointer")
+
+// FeatureInto stores the Feature with the given label into the value pointed
+// to by ptr, which must be a non-nil pointer to a type that the Feature is
+// assignable to -- usually a pointer to the Feature's concrete type (or to an
+// interface it implements). For example:
+//
+//	var db *dbFeature
+//	if err := cfg.FeatureInto("db", &db); err != nil {
+//	  ...
+//	}
+//
+// An UnknownFeatureError is returned if no such Feature exists and an
+// UnavailableFeatureError if the Feature is disabled or is a non-selected
+// member of a "oneof" set. If the Feature cannot be assigned to *ptr, a
+// FeatureTypeError is returned.
+func (c *Config) FeatureInto(l Label, ptr interface{}) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	fd := c.defn(l)
+	if fd == nil || l == "" {
+		return unknownFeatureError(l)
+	}
+
+	if fd.disabled {
+		return unavailableFeatureError(l, "is disabled")
+	}
+
+	if fd.oneof != "" && c.oomap[fd.oneof] != l {
+		return unavailableFeatureError(l, "is not selected for "+fd.oneof)
+	}
+
+	return assignFeature(l, fd.Feature, ptr)
+}
+
+// OneOfInto is similar to FeatureInto but stores the Feature currently
+// selected for the named "oneof" set. A NoSelectionError is returned if the
+// set is unknown or has no selected member.
+func (c *Config) OneOfInto(name string, ptr interface{}) error {
+	c.mu.RLock()
+	l, ok := c.oomap[name]
+	c.mu.RUnlock()
+
+	if !ok {
+		return noSelectionError(name, c.ooset[name])
+	}
+
+	return c.FeatureInto(l, ptr)
+}
+
+func assignFeature(l Label, f Feature, ptr interface{}) error {
+	pv := reflect.ValueOf(ptr)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() {
+		return errBadTarget
+	}
+
+	target := pv.Elem()
+
+	fv := reflect.ValueOf(f)
+	if !fv.Type().AssignableTo(target.Type()) {
+		return featureTypeError(l, target.Type(), fv.Type())
+	}
+
+	target.Set(fv)
+
+	return nil
+}
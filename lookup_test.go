@@ -0,0 +1,75 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFeatureInto(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+	RegisterOneOf("otf", "feata", func() Feature { return new(oneofFeatureA) })
+	RegisterOneOf("otf", "featb", func() Feature { return new(oneofFeatureB) })
+
+	buf := bytes.NewBufferString(`{ "featb": { "option": "value1" } }`)
+
+	c := New("lookuptest", FromReader("json", buf))
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var tf *testFeature
+	if err := c.FeatureInto("feat", &tf); err != nil || tf != c.Feature("feat") {
+		t.Errorf("c.FeatureInto(%q) == (%v, %p); Wanted (%v, %p)", "feat", err, tf, nil, c.Feature("feat"))
+	}
+
+	var fb *oneofFeatureB
+	if err := c.OneOfInto("otf", &fb); err != nil || fb == nil || fb.Option != "value1" {
+		t.Errorf("c.OneOfInto(%q) == (%v, %#v); Wanted (%v, %q)", "otf", err, fb, nil, "value1")
+	}
+
+	var fa *oneofFeatureA
+
+	errTests := []struct {
+		desc string
+		err  error
+		want error
+	}{
+		{"unknown", c.FeatureInto("nope", &tf), unknownFeatureError("nope")},
+		{"unselected", c.FeatureInto("feata", &fa), unavailableFeatureError("feata", "is not selected for otf")},
+		{"wrong-type", c.FeatureInto("feat", &fa), featureTypeError("feat", reflect.TypeOf(fa), reflect.TypeOf(tf))},
+		{"bad-target", c.FeatureInto("feat", (*testFeature)(nil)), errBadTarget},
+		{"no-oneof", c.OneOfInto("nope", &fa), noSelectionError("nope", nil)},
+	}
+
+	for _, tc := range errTests {
+		if !reflect.DeepEqual(tc.err, tc.want) {
+			t.Errorf("[%s] error == %v; Wanted %v", tc.desc, tc.err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"toolman.org/base/log/v2"
+)
+
+// versionKey is the top-level config key declaring the version of a config
+// file's layout. Files lacking this key are considered to be version 0.
+const versionKey = "config-version"
+
+// MigrationFunc updates, in place, the raw settings map for a single Feature
+// from one config version to the next. For the base Feature, the map holds
+// all top-level settings. Note that all keys are lower-cased.
+type MigrationFunc func(map[string]interface{}) error
+
+type migration struct {
+	from int
+	to   int
+	fn   MigrationFunc
+}
+
+// RegisterMigration registers a MigrationFunc for updating the settings of
+// the Feature labeled l from config version `from` to version `to`. Use the
+// empty label for the base Feature.
+//
+// When a config file is loaded, the migrations for each Feature are applied
+// in sequence starting with the one registered for the file's "config-version"
+// and continuing with the one registered for the version reached by the
+// previous migration -- until no further migrations are found. Migrations are
+// applied before "oneof" detection and unmarshalling.
+//
+// ErrInvalidMigration is returned if `to` is not greater than `from` and
+// ErrDuplicateMigration is returned if a migration has already been
+// registered for the same label and `from` version. As with Register,
+// ErrRegistrationClosed is returned if called after Features have been
+// reified.
+func RegisterMigration(l Label, from, to int, fn MigrationFunc) error {
+	if to <= from {
+		return ErrInvalidMigration
+	}
+
+	return registry.addMigration(l, &migration{from, to, fn})
+}
+
+func (fd *featureDefn) migrationFrom(version int) *migration {
+	for _, m := range fd.migs {
+		if m.from == version {
+			return m
+		}
+	}
+	return nil
+}
+
+// migrate applies all relevant migrations to the (normalized) raw settings.
+func (c *Config) migrate(raw map[string]interface{}) error {
+	return c.applyMigrations(raw, true)
+}
+
+// applyMigrations applies all relevant migrations to raw, logging and
+// reporting each step only if record is true.
+func (c *Config) applyMigrations(raw map[string]interface{}, record bool) error {
+	version, err := configVersion(raw)
+	if err != nil {
+		return err
+	}
+
+	for _, fd := range c.defs {
+		if len(fd.migs) == 0 {
+			continue
+		}
+
+		m := raw
+		if fd.label != "" {
+			var ok bool
+			if m, ok = raw[strings.ToLower(string(fd.label))].(map[string]interface{}); !ok {
+				continue
+			}
+		}
+
+		for mg := fd.migrationFrom(version); mg != nil; mg = fd.migrationFrom(mg.to) {
+			if err := mg.fn(m); err != nil {
+				return migrationError(fd.label, mg.from, mg.to, err)
+			}
+
+			if !record {
+				continue
+			}
+
+			log.Infof("Migrated config for %q from version %d to %d", fd.label, mg.from, mg.to)
+			c.report.Migrations = append(c.report.Migrations, &MigrationStep{fd.label, mg.from, mg.to})
+		}
+	}
+
+	return nil
+}
+
+func configVersion(raw map[string]interface{}) (int, error) {
+	switch v := raw[versionKey].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %v", versionKey, v, err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid %s: %v", versionKey, v)
+	}
+}
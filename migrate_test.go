@@ -0,0 +1,76 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestMigrations(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	if err := RegisterMigration("feat", 2, 2, nil); err != ErrInvalidMigration {
+		t.Errorf("RegisterMigration(2, 2) == %v; Wanted %v", err, ErrInvalidMigration)
+	}
+
+	RegisterMigration("feat", 1, 2, func(m map[string]interface{}) error {
+		m["thing-one"] = m["thing"]
+		delete(m, "thing")
+		return nil
+	})
+
+	RegisterMigration("feat", 2, 3, func(m map[string]interface{}) error {
+		m["other"] = m["other"].(float64) * 2
+		return nil
+	})
+
+	if err := RegisterMigration("feat", 2, 4, nil); err != ErrDuplicateMigration {
+		t.Errorf("RegisterMigration(2, 4) == %v; Wanted %v", err, ErrDuplicateMigration)
+	}
+
+	text := `{ "config-version": 1, "feat": { "thing": "x", "other": 2 } }`
+
+	c := New("migratetest", StrictKeys, FromReader("json", bytes.NewBufferString(text)))
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tf := c.Feature("feat").(*testFeature)
+
+	if tf.ThingOne != "x" || tf.Other != 4 {
+		t.Errorf("ThingOne, Other == %q, %d; Wanted %q, %d", tf.ThingOne, tf.Other, "x", 4)
+	}
+
+	want := []*MigrationStep{{"feat", 1, 2}, {"feat", 2, 3}}
+
+	if got := c.Report().Migrations; !reflect.DeepEqual(got, want) {
+		t.Errorf("c.Report().Migrations == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(want))
+	}
+}
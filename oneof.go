@@ -0,0 +1,123 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"sort"
+	"strings"
+
+	"toolman.org/base/log/v2"
+)
+
+// selectorKey returns the config key used to explicitly select the active
+// member of the "oneof" set with the given name (e.g. "storage.type"). This
+// key may also be set using the flag named for the set (e.g. "--storage") or
+// its corresponding environment variable (e.g. "${PREFIX}_STORAGE_TYPE").
+func selectorKey(name string) string {
+	return name + ".type"
+}
+
+func (c *Config) oneOfNames() []string {
+	var list []string
+	for name := range c.ooset {
+		list = append(list, name)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+// selectOneOfs determines which Feature is active for each "oneof" set. The
+// provided map should contain all currently available settings; it's used to
+// discover which Features have been configured.
+func (c *Config) selectOneOfs(all map[string]interface{}) error {
+	for _, name := range c.oneOfNames() {
+		if err := c.selectOneOf(name, all); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) selectOneOf(name string, all map[string]interface{}) error {
+	members := c.ooset[name]
+
+	// If the selector key has been set, it alone determines which member is
+	// active; configuration for any other members is ignored.
+	if sel := Label(c.GetString(selectorKey(name))); sel != "" {
+		if !hasLabel(members, sel) {
+			return unknownOneOfError(name, sel, members)
+		}
+
+		for _, l := range members {
+			if _, has := all[string(l)]; has && l != sel {
+				log.Warningf("Ignoring configuration for %q; %q is selected as %q", l, sel, name)
+			}
+		}
+
+		log.Infof("Using %q as %q", sel, name)
+		c.oomap[name] = sel
+		return nil
+	}
+
+	// Otherwise, if a member's label is found in the `all` map (meaning we have
+	// config values for that Feature) then it is the configured Feature for
+	// this "oneof" set. There can be only one of these. Disabled members are
+	// not considered.
+	for _, l := range members {
+		if _, has := all[string(l)]; !has {
+			continue
+		}
+
+		if fd := c.defn(l); fd != nil && fd.disabled {
+			continue
+		}
+
+		if oo, ok := c.oomap[name]; ok {
+			delete(c.oomap, name)
+			return multipleOneOfError(name, oo, l)
+		}
+
+		log.Infof("Using %q as %q", l, name)
+		c.oomap[name] = l
+	}
+
+	return nil
+}
+
+func hasLabel(list []Label, l Label) bool {
+	for _, x := range list {
+		if x == l {
+			return true
+		}
+	}
+	return false
+}
+
+func joinLabels(list []Label) string {
+	s := make([]string, len(list))
+	for i, l := range list {
+		s[i] = string(l)
+	}
+	return strings.Join(s, ", ")
+}
@@ -40,12 +40,19 @@ func TestOneOf(t *testing.T) {
 	fb := `"featb": { "option": "value1" }`
 	fc := `"featc": { "other": "thing"}`
 	bf := fa + "," + fb
+	sa := `"otf": { "type": "feata" }`
+	sx := `"otf": { "type": "featx" }`
+	bb := `"featb": { "option": { "bad": 1 } }`
 
 	tests := map[string]*ooTestcase{
-		"a-want-a":   &ooTestcase{fa, &oneofFeatureA{Option: "value1"}, nil},
-		"b-want-b":   &ooTestcase{fb, &oneofFeatureB{Option: "value1"}, nil},
-		"c-want-nil": &ooTestcase{fc, nil, nil},
-		"ab-err":     &ooTestcase{bf, nil, multipleOneOfError("otf", "feata", "featb")},
+		"a-want-a":       &ooTestcase{fa, &oneofFeatureA{Option: "value1"}, nil},
+		"b-want-b":       &ooTestcase{fb, &oneofFeatureB{Option: "value1"}, nil},
+		"c-want-nil":     &ooTestcase{fc, nil, nil},
+		"ab-err":         &ooTestcase{bf, nil, multipleOneOfError("otf", "feata", "featb")},
+		"ab-select-a":    &ooTestcase{bf + "," + sa, &oneofFeatureA{Option: "value1"}, nil},
+		"select-a-only":  &ooTestcase{sa, &oneofFeatureA{}, nil},
+		"select-a-bad-b": &ooTestcase{sa + "," + bb, &oneofFeatureA{}, nil},
+		"select-x-err":   &ooTestcase{fa + "," + sx, nil, unknownOneOfError("otf", "featx", []Label{"feata", "featb"})},
 	}
 
 	for name, tc := range tests {
@@ -73,6 +80,51 @@ func (tc *ooTestcase) test(t *testing.T) {
 	}
 }
 
+type conflictBase struct {
+	Otf string `cfg:"otf"`
+}
+
+func (b *conflictBase) FlagSet(fs *pflag.FlagSet) {
+	fs.StringVar(&b.Otf, "otf", "", "Conflicts with the otf selector flag")
+}
+
+func (b *conflictBase) Validate(context.Context) error { return nil }
+
+func TestSelectorFlagConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   string
+		options []Option
+	}{
+		{"builtin", "config-file", nil},
+		{"base", "otf", []Option{Base(new(conflictBase))}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reset := useTestRegistry()
+			defer reset()
+
+			RegisterOneOf(tc.group, "feata", func() Feature { return new(oneofFeatureA) })
+
+			c := New("conflicttest", tc.options...)
+
+			want := flagConflictError(tc.group)
+			if err := c.Load(context.Background()); !reflect.DeepEqual(err, want) {
+				t.Errorf("c.Load() == (%v); Wanted (%v)", err, want)
+			}
+
+			if err := c.Reload(context.Background()); !reflect.DeepEqual(err, want) {
+				t.Errorf("c.Reload() == (%v); Wanted (%v)", err, want)
+			}
+
+			if cr := c.Check(context.Background()); cr.OK || len(cr.Errors) != 1 || cr.Errors[0] != want.Error() {
+				t.Errorf("c.Check().Errors == %q; Wanted [%q]", cr.Errors, want.Error())
+			}
+		})
+	}
+}
+
 type oneofFeature struct{}
 
 func (f *oneofFeature) FlagSet(*pflag.FlagSet)         {}
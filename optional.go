@@ -0,0 +1,62 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import "github.com/spf13/pflag"
+
+// enabledKey is the conventional key used to switch Optional Features on or
+// off.
+const enabledKey = "enabled"
+
+func (fd *featureDefn) optional() bool {
+	if fd.label == "" {
+		return false
+	}
+
+	_, ok := fd.Feature.(Optional)
+	return ok
+}
+
+// addEnabled adds a default value and a flag for the "enabled" key of an
+// Optional Feature -- unless the Feature has provided its own flag.
+func (fd *featureDefn) addEnabled(fs *pflag.FlagSet) {
+	if !fd.optional() {
+		return
+	}
+
+	def := fd.Feature.(Optional).EnabledByDefault()
+
+	fd.defaults[fd.label.Key(enabledKey)] = def
+
+	if fs.Lookup(enabledKey) == nil {
+		fs.Bool(enabledKey, def, "Enable this feature")
+	}
+}
+
+// Enabled returns false if the Feature with the given label is an Optional
+// Feature that has been disabled or if no such Feature exists.
+func (c *Config) Enabled(l Label) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	fd := c.defn(l)
+	return fd != nil && !fd.disabled
+}
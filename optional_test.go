@@ -0,0 +1,86 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type optionalFeature struct {
+	Addr string `cfg:"addr"`
+	def  bool
+}
+
+func (of *optionalFeature) FlagSet(*pflag.FlagSet) {}
+func (of *optionalFeature) EnabledByDefault() bool { return of.def }
+
+func (of *optionalFeature) Validate(context.Context) error {
+	if of.Addr == "" {
+		return errors.New("missing addr")
+	}
+	return nil
+}
+
+func TestOptional(t *testing.T) {
+	tests := []struct {
+		def  bool
+		text string
+		want bool
+	}{
+		{false, `{}`, false},
+		{true, `{ "opt": { "enabled": false } }`, false},
+		{true, `{ "opt": { "enabled": false, "addr": { "bad": 1 } } }`, false},
+		{false, `{ "opt": { "enabled": true, "addr": ":80" } }`, true},
+		{true, `{ "opt": { "addr": ":80" } }`, true},
+	}
+
+	for _, tc := range tests {
+		reset := useTestRegistry()
+
+		def := tc.def
+		Register("opt", func() Feature { return &optionalFeature{def: def} })
+
+		c := New("optionaltest", FromReader("json", bytes.NewBufferString(tc.text)))
+
+		if c.flags.Lookup("opt.enabled") == nil {
+			t.Errorf("missing flag: --opt.enabled")
+		}
+
+		if err := c.Load(context.Background()); err != nil {
+			t.Errorf("[%s] c.Load() == %v; Wanted %v", tc.text, err, nil)
+		}
+
+		if got := c.Enabled("opt"); got != tc.want {
+			t.Errorf("[%s] c.Enabled(%q) == %t; Wanted %t", tc.text, "opt", got, tc.want)
+		}
+
+		if got := c.Feature("opt") != nil; got != tc.want {
+			t.Errorf("[%s] c.Feature(%q) != nil == %t; Wanted %t", tc.text, "opt", got, tc.want)
+		}
+
+		reset()
+	}
+}
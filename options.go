@@ -29,8 +29,17 @@ type Option interface {
 type cfgOptions struct {
 	base      Feature
 	ocfErr    onConfFileErr
+	invalid   invalidPolicy
+	keys      keyPolicy
 	envPrefix string
-	cfgReader *cfgReader
+	readers   []Source
+	rdrMode   readerMode
+	sources   []*sourceOpt
+	envFiles  []string
+	defConfig Source
+	cfgPath   []string
+	xcfgPath  []string
+	effHelp   bool
 }
 
 //--------------------------------------
@@ -63,6 +72,42 @@ func (o onConfFileErr) setopt(c *cfgOptions) {
 
 //--------------------------------------
 
+// The above options determine how Load treats a config file that cannot be
+// found (or read); these determine how it treats one that is found but
+// cannot be parsed. With RejectInvalidConfigFile (the default) Load fails
+// with a *ConfigParseError; WarnOnInvalidConfigFile logs the error and
+// proceeds as if no config file were found.
+const (
+	RejectInvalidConfigFile invalidPolicy = iota
+	WarnOnInvalidConfigFile
+)
+
+type invalidPolicy int
+
+func (p invalidPolicy) setopt(c *cfgOptions) {
+	c.invalid = p
+}
+
+//--------------------------------------
+
+// These options determine how Load treats config file keys (or prefixed
+// environment variables) that don't map to a `cfg` tagged field of any
+// registered Feature. With StrictKeys, Load fails with an UnknownKeysError;
+// WarnOnUnknownKeys logs the same information instead.
+const (
+	AllowUnknownKeys keyPolicy = iota
+	WarnOnUnknownKeys
+	StrictKeys
+)
+
+type keyPolicy int
+
+func (k keyPolicy) setopt(c *cfgOptions) {
+	c.keys = k
+}
+
+//--------------------------------------
+
 func EnvPrefix(s string) Option {
 	return envPrefix(s)
 }
@@ -75,15 +120,144 @@ func (e envPrefix) setopt(c *cfgOptions) {
 
 //--------------------------------------
 
+// FromReader provides config data of the given type (e.g. "yaml" or "json")
+// to be read from readr. If typ is empty, the format is detected from the
+// data itself. This option may be given multiple times; the data from each
+// reader is merged in the order given.
+//
+// By default, reader data replaces the config file search entirely. See
+// LayerReaders for an alternative.
 func FromReader(typ string, readr io.Reader) Option {
-	return &cfgReader{typ, readr}
+	return &readerOpt{ReaderSource(typ, readr)}
 }
 
-type cfgReader struct {
-	typ   string
-	readr io.Reader
+type readerOpt struct {
+	src Source
 }
 
-func (r *cfgReader) setopt(c *cfgOptions) {
-	c.cfgReader = r
+func (r *readerOpt) setopt(c *cfgOptions) {
+	c.readers = append(c.readers, r.src)
+}
+
+//--------------------------------------
+
+// These options determine how data from FromReader is combined with the
+// config file. With ReplaceFileSearch (the default) no config file is
+// searched for or loaded. With LayerReaders the config file is loaded as
+// normal and reader data is merged on top of it.
+const (
+	ReplaceFileSearch readerMode = iota
+	LayerReaders
+)
+
+type readerMode int
+
+func (m readerMode) setopt(c *cfgOptions) {
+	c.rdrMode = m
+}
+
+//--------------------------------------
+
+// WithSource adds a Source of configuration settings. The onErr parameter
+// determines how errors from this Source are handled using the same
+// constants as for the config file (i.e. RequireConfigFile,
+// WarnOnConfigFileErrors or IgnoreConfigFileErrors).
+func WithSource(src Source, onErr onConfFileErr) Option {
+	return &sourceOpt{src, onErr}
+}
+
+type sourceOpt struct {
+	src   Source
+	onErr onConfFileErr
+}
+
+func (s *sourceOpt) setopt(c *cfgOptions) {
+	c.sources = append(c.sources, s)
+}
+
+//--------------------------------------
+
+// EnvFile adds an environment file (aka "dotenv" file) to be loaded. See the
+// --env-file flag for details.
+func EnvFile(path string) Option {
+	return envFile(path)
+}
+
+type envFile string
+
+func (e envFile) setopt(c *cfgOptions) {
+	c.envFiles = append(c.envFiles, string(e))
+}
+
+//--------------------------------------
+
+// DefaultConfig provides config data of the given format (e.g. "yaml") that
+// is bundled into the binary -- for example, using "//go:embed". If format
+// is empty, it is detected from the data. These settings form the lowest
+// configuration layer; they override the default values from each Feature's
+// struct tags but are themselves overridden by all config files, Sources,
+// environment variables and flags.
+func DefaultConfig(format string, data []byte) Option {
+	return &defConfigOpt{&readerSource{basicSource: basicSource{"default-config"}, typ: format, data: data}}
+}
+
+type defConfigOpt struct {
+	src Source
+}
+
+func (d *defConfigOpt) setopt(c *cfgOptions) {
+	c.defConfig = d.src
+}
+
+//--------------------------------------
+
+// ConfigPath replaces the default list of directories searched for the config
+// file (see the --config-path flag). Directories are searched in the order
+// given and may contain environment variables or a leading "~".
+func ConfigPath(dirs ...string) Option {
+	return &cfgPathOpt{dirs, true}
+}
+
+// ExtraConfigPath appends the given directories to the default list of
+// directories searched for the config file.
+func ExtraConfigPath(dirs ...string) Option {
+	return &cfgPathOpt{dirs, false}
+}
+
+type cfgPathOpt struct {
+	dirs    []string
+	replace bool
+}
+
+func (p *cfgPathOpt) setopt(c *cfgOptions) {
+	if p.replace {
+		c.cfgPath = append([]string{}, p.dirs...)
+		c.xcfgPath = nil
+	} else {
+		c.xcfgPath = append(c.xcfgPath, p.dirs...)
+	}
+}
+
+// configPath returns the default value for the --config-path flag.
+func (o *cfgOptions) configPath(name string) []string {
+	list := o.cfgPath
+	if list == nil {
+		list = defaultCfgPath(name)
+	}
+
+	return append(append([]string{}, list...), o.xcfgPath...)
+}
+
+//--------------------------------------
+
+// EffectiveHelp causes the flag usage message (e.g. from --help) to load all
+// config settings first and then show, for each flag, its effective value
+// and where that value came from (e.g. "file", "env" or "default") instead
+// of only its compiled-in default.
+const EffectiveHelp = effectiveHelp(true)
+
+type effectiveHelp bool
+
+func (e effectiveHelp) setopt(c *cfgOptions) {
+	c.effHelp = bool(e)
 }
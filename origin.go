@@ -0,0 +1,271 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envKeyReplacer maps config keys to their environment variable equivalents.
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// Possible origins for a config value, as reported by Config.origin.
+const (
+	originFlag    = "flag"
+	originEnv     = "env"
+	originEnvFile = "env-file"
+	originFile    = "file"
+	originSource  = "source"
+	originBundle  = "default-config"
+	originDefault = "default"
+)
+
+// secretMask replaces the value of all secret fields in effective config output.
+const secretMask = "********"
+
+// envName returns the name of the environment variable that, through viper's
+// AutomaticEnv, will provide a value for the given config key.
+func (c *Config) envName(key string) string {
+	if p := c.opts.envPrefix; p != "" {
+		key = p + "_" + key
+	}
+
+	return strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// origin returns a short description of where the current value for key was
+// found.
+func (c *Config) origin(key string) string {
+	if c.flagChanged(key) || c.setKeys[strings.ToLower(key)] {
+		return originFlag
+	}
+
+	for a, ak := range c.alias {
+		if ak != key {
+			continue
+		}
+		if _, ok := lookupEnv(c.envName(a)); ok {
+			return originEnv
+		}
+	}
+
+	if _, ok := lookupEnv(c.envName(key)); ok {
+		return originEnv
+	}
+
+	if c.dotKeys[strings.ToLower(key)] {
+		return originEnvFile
+	}
+
+	if o := c.rawOrigin(key); o != "" {
+		return o
+	}
+
+	if _, ok := lookupKey(c.bundle, key); ok {
+		return originBundle
+	}
+
+	return originDefault
+}
+
+// rawOrigin returns originFile if the value for key in the raw settings was
+// read from the config file (by way of key or one of its aliases) or
+// originSource if it came from elsewhere (i.e. FromReader, WithSource or
+// KeyPerFile data). If key has no raw setting, the empty string is returned.
+func (c *Config) rawOrigin(key string) string {
+	rv, ok := lookupKey(c.raw, key)
+	if !ok {
+		return ""
+	}
+
+	names := []string{key}
+	for a, ak := range c.alias {
+		if ak == key {
+			names = append(names, a)
+		}
+	}
+
+	for _, name := range names {
+		if fv, ok := lookupKey(c.fdata, name); ok && sameValue(fv, rv) {
+			return originFile
+		}
+	}
+
+	return originSource
+}
+
+// flagChanged returns true if a value for key was given on the command line
+// by its own flag, its OneOf selector flag or one of its alias flags.
+func (c *Config) flagChanged(key string) bool {
+	changed := func(name string) bool {
+		f := c.flags.Lookup(name)
+		return f != nil && f.Changed
+	}
+
+	if changed(key) {
+		return true
+	}
+
+	for name, sk := range c.sels {
+		if sk == key && changed(name) {
+			return true
+		}
+	}
+
+	for a, ak := range c.alias {
+		if ak == key && changed(a) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSecret returns true if key refers to (or is nested beneath) a field
+// tagged as a secret.
+func (c *Config) isSecret(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, fd := range c.defs {
+		for _, fi := range fd.fields {
+			if !fi.secret {
+				continue
+			}
+
+			sk := strings.ToLower(fd.label.Key(fi.key))
+			if key == sk || strings.HasPrefix(key, sk+".") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// settings returns a flattened map of all current settings keyed by their
+// fully qualified config keys. Secret values are masked.
+func (c *Config) settings() map[string]interface{} {
+	m := make(map[string]interface{})
+
+	for _, k := range c.AllKeys() {
+		if c.isSecret(k) {
+			m[k] = secretMask
+		} else {
+			m[k] = normalize(c.Get(k))
+		}
+	}
+
+	return m
+}
+
+// lookupEnv behaves like os.LookupEnv except that, like viper, it treats
+// empty values as unset.
+func lookupEnv(name string) (string, bool) {
+	v, ok := os.LookupEnv(name)
+	return v, ok && v != ""
+}
+
+// lookupKey searches the nested map m for the dot separated key.
+func lookupKey(m map[string]interface{}, key string) (interface{}, bool) {
+	parts := strings.Split(strings.ToLower(key), ".")
+
+	for i, p := range parts {
+		v, ok := m[p]
+		if !ok {
+			return nil, false
+		}
+
+		if i == len(parts)-1 {
+			return v, true
+		}
+
+		if m, ok = normalize(v).(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// setKey stores val in the nested map m at the dot separated key, creating
+// intermediate maps as needed. Note that m must already be normalized.
+func setKey(m map[string]interface{}, key string, val interface{}) {
+	parts := strings.Split(strings.ToLower(key), ".")
+
+	for _, p := range parts[:len(parts)-1] {
+		sub, ok := m[p].(map[string]interface{})
+		if !ok {
+			sub = make(map[string]interface{})
+			m[p] = sub
+		}
+		m = sub
+	}
+
+	m[parts[len(parts)-1]] = val
+}
+
+// deleteKey removes the dot separated key from the nested map m. Note that m
+// must already be normalized.
+func deleteKey(m map[string]interface{}, key string) {
+	parts := strings.Split(strings.ToLower(key), ".")
+
+	for _, p := range parts[:len(parts)-1] {
+		sub, ok := m[p].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = sub
+	}
+
+	delete(m, parts[len(parts)-1])
+}
+
+// normalize recursively converts any map[interface{}]interface{} values
+// (as produced by the YAML parser) into map[string]interface{}.
+func normalize(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprintf("%v", k)] = normalize(val)
+		}
+		return m
+
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = normalize(val)
+		}
+		return m
+
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, val := range v {
+			l[i] = normalize(val)
+		}
+		return l
+
+	default:
+		return in
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"toolman.org/base/log/v2"
+)
+
+// SearchPath returns the list of directories searched for the config file
+// during the most recent call to Load -- or, if Load has not yet been called,
+// the list that will be searched. The list is empty if the search was
+// bypassed (e.g. by --config-file).
+func (c *Config) SearchPath() []string {
+	if c.loaded || c.lderr != nil {
+		return append([]string(nil), c.spath...)
+	}
+
+	if c.file != "" {
+		return nil
+	}
+
+	return c.searchPath()
+}
+
+// searchPath returns the expanded, de-duplicated list of directories
+// provided by AddConfigPath and --config-path followed by the current
+// directory.
+func (c *Config) searchPath() []string {
+	var list []string
+	list = append(list, c.xpath...)
+	list = append(list, c.path...)
+	list = append(list, ".")
+
+	seen := make(map[string]bool)
+
+	var out []string
+	for _, d := range list {
+		if d = expandPath(d); d != "" && !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+
+	return out
+}
+
+// findConfigFile searches each of dirs for a config file with the given base
+// name and any extension supported by viper, returning the first one found.
+func findConfigFile(name string, dirs []string) (string, error) {
+	for _, d := range dirs {
+		log.Infof("Searching for config in: %q", d)
+		for _, ext := range viper.SupportedExts {
+			file := filepath.Join(d, name+"."+ext)
+			if fi, err := os.Stat(file); err == nil && !fi.IsDir() {
+				return file, nil
+			}
+		}
+	}
+
+	return "", configNotFoundError(name, dirs, viper.SupportedExts)
+}
+
+// defaultCfgPath returns the standard list of config directories for a
+// program with the given name; these are (in order):
+//
+//	<bindir>
+//	<bindir>/conf
+//	$XDG_CONFIG_HOME/<name>    (if $XDG_CONFIG_HOME is set)
+//	~/.config/<name>
+//	/etc/<name>
+//
+// ...where <bindir> is the directory containing the program's binary.
+func defaultCfgPath(name string) []string {
+	list := []string{BinDir(), BinDir("conf")}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		list = append(list, filepath.Join(xdg, name))
+	}
+
+	return append(list, filepath.Join("~", ".config", name), filepath.Join("/etc", name))
+}
+
+// binDir returns the directory containing the current program's binary (with
+// symlinks resolved) falling back to the directory portion of os.Args[0].
+func binDir() string {
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+
+	if err != nil {
+		return filepath.Dir(os.Args[0])
+	}
+
+	return filepath.Dir(exe)
+}
+
+// expandPath expands any environment variables and a leading "~" in path.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+
+	if path == "~" || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+
+	if path == "" {
+		return ""
+	}
+
+	return filepath.Clean(path)
+}
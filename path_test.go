@@ -0,0 +1,159 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	defer setenv("PATHTEST_DIR", "/opt/pathtest")()
+
+	for in, want := range map[string]string{
+		"~":                    home,
+		"~/conf":               filepath.Join(home, "conf"),
+		"~user/conf":           "~user/conf",
+		"$PATHTEST_DIR/conf/":  "/opt/pathtest/conf",
+		"${PATHTEST_DIR}/../x": "/opt/x",
+		"":                     "",
+	} {
+		if got := expandPath(in); got != want {
+			t.Errorf("expandPath(%q) == %q; Wanted %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	defer setenv("XDG_CONFIG_HOME", "/xdg")()
+
+	c := New("pathtest")
+
+	want := []string{BinDir(), BinDir("conf"), "/xdg/pathtest"}
+	if got := c.SearchPath(); !reflect.DeepEqual(got[:3], want) || got[len(got)-2] != "/etc/pathtest" || got[len(got)-1] != "." {
+		t.Errorf("c.SearchPath() == %q; Wanted prefix %q", got, want)
+	}
+
+	c = New("pathtest", ConfigPath("/a", "/b"), ExtraConfigPath("/c"))
+	c.AddConfigPath("/first")
+	c.AddConfigPath("/a")
+
+	want = []string{"/first", "/a", "/b", "/c", "."}
+	if got := c.SearchPath(); !reflect.DeepEqual(got, want) {
+		t.Errorf("c.SearchPath() == %q; Wanted %q", got, want)
+	}
+
+	c.file = "testdata/testbase.yml"
+	if got := c.SearchPath(); got != nil {
+		t.Errorf("c.SearchPath() == %q; Wanted nil", got)
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	dir, err := ioutil.TempDir("", "basecfg-errtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := New("errtest", ConfigPath(dir), IgnoreConfigFileErrors)
+	if err := c.Load(context.Background()); err != nil {
+		t.Errorf("c.Load() == %v; Wanted nil", err)
+	}
+
+	c = New("errtest", ConfigPath(dir))
+	if nf, ok := c.Load(context.Background()).(*ConfigNotFoundError); !ok {
+		t.Errorf("c.Load() == %v; Wanted %T", nf, nf)
+	} else if want := []string{dir, "."}; !reflect.DeepEqual(nf.Dirs, want) || len(nf.Exts) == 0 {
+		t.Errorf("ConfigNotFoundError Dirs: %q Exts: %q; Wanted Dirs: %q", nf.Dirs, nf.Exts, want)
+	}
+
+	tests := []struct {
+		ext  string
+		text string
+		line int
+	}{
+		{"yaml", "feat:\n  thing-one: a\n  other: [1, 2\n", 3},
+		{"yml", "feat:\n  other: 1\n bad: 2\n", 2},
+		{"json", "{\n  \"feat\": {\n    \"other\": 1,\n  }\n}\n", 4},
+		{"toml", "[feat]\nother = 1\nthing-one = \n", 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ext, func(t *testing.T) {
+			file := filepath.Join(dir, "errtest."+tc.ext)
+			if err := ioutil.WriteFile(file, []byte(tc.text), 0644); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(file)
+
+			c := New("errtest", ConfigPath(dir), IgnoreConfigFileErrors)
+
+			err := c.Load(context.Background())
+
+			pe, ok := err.(*ConfigParseError)
+			if !ok {
+				t.Fatalf("c.Load() == %v; Wanted %T", err, pe)
+			}
+
+			if pe.File != file || pe.Line != tc.line {
+				t.Errorf("c.Load() == %v; Wanted %s:%d", pe, file, tc.line)
+			}
+
+			c = New("errtest", ConfigPath(dir), WarnOnInvalidConfigFile)
+			if err := c.Load(context.Background()); err != nil {
+				t.Errorf("c.Load() with WarnOnInvalidConfigFile == %v; Wanted nil", err)
+			}
+		})
+	}
+}
+
+// setenv sets the named environment variable and returns a func that will
+// restore it to its original state.
+func setenv(name, value string) func() {
+	orig, ok := os.LookupEnv(name)
+	os.Setenv(name, value)
+
+	return func() {
+		if ok {
+			os.Setenv(name, orig)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
@@ -22,14 +22,19 @@ package basecfg
 
 import (
 	"errors"
+	"reflect"
 	"sort"
+	"strings"
 	"sync"
+
+	"github.com/spf13/pflag"
 )
 
 var registry featureRegistry
 
 type featureRegistry struct {
 	defs    map[Label]*featureDefn
+	migs    map[Label][]*migration
 	reified bool
 	sync.Mutex
 }
@@ -55,6 +60,37 @@ func (r *featureRegistry) add(fd *featureDefn) error {
 	return nil
 }
 
+func (r *featureRegistry) addMigration(l Label, m *migration) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.reified {
+		return ErrRegistrationClosed
+	}
+
+	if r.migs == nil {
+		r.migs = make(map[Label][]*migration)
+	}
+
+	for _, x := range r.migs[l] {
+		if x.from == m.from {
+			return ErrDuplicateMigration
+		}
+	}
+
+	r.migs[l] = append(r.migs[l], m)
+
+	return nil
+}
+
+// migrations returns the migrations registered for the given label.
+func (r *featureRegistry) migrations(l Label) []*migration {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.migs[l]
+}
+
 func (r *featureRegistry) reify() []*featureDefn {
 	r.Lock()
 	defer r.Unlock()
@@ -67,10 +103,13 @@ func (r *featureRegistry) reify() []*featureDefn {
 
 	list := make([]*featureDefn, len(r.defs))
 
+	// Each caller gets its own copy of every featureDefn (and thus, its own
+	// set of Features) so that multiple Configs may coexist.
 	for i, lbl := range r.labels() {
-		f := r.defs[lbl]
+		f := *r.defs[lbl]
 		f.reify()
-		list[i] = f
+		f.migs = r.migs[lbl]
+		list[i] = &f
 	}
 
 	return list
@@ -96,9 +135,103 @@ type featureDefn struct {
 	oneof    string
 	create   FeatureFunc
 	defaults map[string]interface{}
+	fields   []*fieldInfo
+	migs     []*migration
+	flags    []*pflag.Flag
+	initial  Feature // a copy of the base Feature as given to New
+
+	// Load results
+	configured bool
+	disabled   bool
+	validated  bool
+	verr       error
+
 	Feature
 }
 
+// fork returns a copy of fd, without the results of any previous Load,
+// holding a new instance of its Feature. Since a base Feature cannot be
+// recreated, it is instead copied -- with its config fields reset to their
+// initial values so that it loads just as the original did.
+func (fd *featureDefn) fork() *featureDefn {
+	nd := *fd
+	nd.configured = false
+	nd.disabled = false
+	nd.validated = false
+	nd.verr = nil
+
+	if fd.create != nil {
+		nd.Feature = fd.create()
+		return &nd
+	}
+
+	nf := copyFeature(fd.Feature)
+	if nf == nil || fd.initial == nil {
+		return &nd
+	}
+
+	iv := reflect.ValueOf(fd.initial).Elem()
+	nv := reflect.ValueOf(nf).Elem()
+
+	for _, fi := range fd.fields {
+		if f := nv.FieldByName(fi.name); f.CanSet() {
+			f.Set(iv.FieldByName(fi.name))
+		}
+	}
+
+	nd.Feature = nf
+
+	return &nd
+}
+
+// copyFeature returns a shallow copy of f -- or nil if f is not a pointer to
+// a struct.
+func copyFeature(f Feature) Feature {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	nv := reflect.New(v.Type().Elem())
+	nv.Elem().Set(v.Elem())
+
+	return nv.Interface().(Feature)
+}
+
+// copyFields copies the value of each of fd's config fields into dst, which
+// must be a pointer to the same type as fd's Feature.
+func (fd *featureDefn) copyFields(dst Feature) {
+	sv := reflect.Indirect(reflect.ValueOf(fd.Feature))
+	dv := reflect.Indirect(reflect.ValueOf(dst))
+
+	if sv.Kind() != reflect.Struct || sv.Type() != dv.Type() {
+		return
+	}
+
+	for _, fi := range fd.fields {
+		if f := dv.FieldByName(fi.name); f.CanSet() {
+			f.Set(sv.FieldByName(fi.name))
+		}
+	}
+}
+
+// configuredIn returns true if the provided settings map contains config
+// values for this Feature.
+func (fd *featureDefn) configuredIn(all map[string]interface{}) bool {
+	if fd.label != "" {
+		_, has := all[string(fd.label)]
+		return has
+	}
+
+	for _, fi := range fd.fields {
+		if _, has := all[strings.ToLower(fi.key)]; has {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (fd *featureDefn) reify() {
 	fd.Feature = fd.create()
 	fd.extractDefaults()
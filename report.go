@@ -0,0 +1,58 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+// LoadReport summarizes noteworthy events from the most recent call to Load.
+type LoadReport struct {
+	// Sources lists the config file and the names of all other Sources
+	// from which settings were read -- in the order they were merged.
+	Sources []string
+
+	// Aliases lists each use of a deprecated key alias.
+	Aliases []*AliasUse
+
+	// Migrations lists each config migration that was applied.
+	Migrations []*MigrationStep
+}
+
+// AliasUse describes the use of a deprecated key alias. Source is one of
+// "file", "env" or "flag".
+type AliasUse struct {
+	Alias  string
+	Key    string
+	Source string
+}
+
+// Report returns the LoadReport for the most recent call to Load.
+func (c *Config) Report() *LoadReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.report
+}
+
+// MigrationStep describes a single config migration applied to the settings
+// for the Feature with the given Label.
+type MigrationStep struct {
+	Label Label
+	From  int
+	To    int
+}
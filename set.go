@@ -0,0 +1,172 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+)
+
+// setFlag is the name of the repeatable flag used to override any config
+// key with a "key=value" argument.
+const setFlag = "set"
+
+// applySets applies each --set argument to its key with the same precedence
+// as an explicit flag. Values are checked against the type of the field they
+// target. It is an error to give a value for the same key with both --set
+// and its own flag, or with --set using both the key and one of its aliases.
+func (c *Config) applySets() error {
+	c.setKeys = make(map[string]bool)
+
+	used := make(map[string]string)
+
+	for _, arg := range c.sets {
+		eq := strings.Index(arg, "=")
+		if eq < 1 {
+			return setError(arg, "", fmt.Errorf("expected key=value"))
+		}
+
+		name := strings.ToLower(strings.TrimSpace(arg[:eq]))
+		val := arg[eq+1:]
+
+		key := name
+		if ak, ok := c.alias[name]; ok {
+			key = strings.ToLower(ak)
+		}
+
+		typ := c.keyType(key)
+		if typ == nil {
+			uk := &UnknownKey{key, originFlag, suggest(key, c.knownKeys())}
+			return setError(arg, key, fmt.Errorf("unknown key %v", uk))
+		}
+
+		if _, err := decodeValue(val, typ); err != nil {
+			return setError(arg, key, err)
+		}
+
+		if c.flagChanged(key) {
+			return setError(arg, key, fmt.Errorf("%q is also set by flag", key))
+		}
+
+		if prev, ok := used[key]; ok && prev != name {
+			alias := name
+			if alias == key {
+				alias = prev
+			}
+			return setError(arg, key, aliasConflictError(key, alias, originFlag))
+		}
+
+		if name != key && used[key] == "" {
+			c.useAlias(name, key, originFlag)
+		}
+
+		used[key] = name
+
+		f := &pflag.Flag{Name: key, Value: newSetValue(val), Changed: true}
+		if err := c.BindPFlag(key, f); err != nil {
+			return setError(arg, key, err)
+		}
+
+		c.setKeys[key] = true
+	}
+
+	return nil
+}
+
+// setValue is the pflag.Value for a synthetic flag used to bind a --set
+// value to its key.
+type setValue string
+
+func newSetValue(s string) *setValue {
+	v := setValue(s)
+	return &v
+}
+
+func (v *setValue) String() string { return string(*v) }
+func (v *setValue) Type() string   { return "string" }
+
+func (v *setValue) Set(s string) error {
+	*v = setValue(s)
+	return nil
+}
+
+// keyType returns the type of the value expected for key -- or nil if key
+// cannot be set.
+func (c *Config) keyType(key string) reflect.Type {
+	for _, sk := range c.sels {
+		if key == sk {
+			return reflect.TypeOf("")
+		}
+	}
+
+	for _, fd := range c.defs {
+		if fd.optional() && key == strings.ToLower(fd.label.Key(enabledKey)) {
+			return reflect.TypeOf(true)
+		}
+
+		for _, fi := range fd.fields {
+			if key == strings.ToLower(fd.label.Key(fi.key)) {
+				return fd.fieldType(fi)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldType returns the type of the struct field described by fi.
+func (fd *featureDefn) fieldType(fi *fieldInfo) reflect.Type {
+	sf, ok := reflect.TypeOf(fd.Feature).Elem().FieldByName(fi.name)
+	if !ok {
+		return nil
+	}
+
+	return sf.Type
+}
+
+// decodeValue decodes in to a value of the given type using the same
+// (weakly typed) rules applied when unmarshaling config data.
+func decodeValue(in interface{}, typ reflect.Type) (interface{}, error) {
+	out := reflect.New(typ)
+
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		WeaklyTypedInput: true,
+		Result:           out.Interface(),
+		TagName:          "cfg",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dec.Decode(in); err != nil {
+		return nil, err
+	}
+
+	return out.Elem().Interface(), nil
+}
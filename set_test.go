@@ -0,0 +1,129 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestSetFlag(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	defer setenv("SETTEST_FEAT_OTHER", "5")()
+
+	tests := []struct {
+		name   string
+		args   []string
+		want   *testFeature
+		origin string
+		err    string
+	}{
+		{"none", nil, &testFeature{ThingOne: "foo", Other: 5, Stuff: "bar"}, originEnv, ""},
+		{"values", []string{"--set", "feat.other=42", "--set", "FEAT.Stuff=a=b"}, &testFeature{ThingOne: "foo", Other: 42, Stuff: "a=b"}, originFlag, ""},
+		{"repeated", []string{"--set", "feat.other=1", "--set", "feat.other=2"}, &testFeature{ThingOne: "foo", Other: 2, Stuff: "bar"}, originFlag, ""},
+		{"flag-conflict", []string{"--feat.thing-one=flag", "--set=feat.thing-one=set"}, nil, "", "also set by flag"},
+		{"bad-type", []string{"--set", "feat.other=abc"}, nil, "", `invalid --set "feat.other=abc"`},
+		{"unknown", []string{"--set", "feat.thing_one=x"}, nil, "", `did you mean "feat.thing-one"?`},
+		{"no-value", []string{"--set", "feat.other"}, nil, "", "expected key=value"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New("settest", FromReader("json", strings.NewReader("{}")))
+
+			if err := c.flags.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := c.Load(context.Background())
+
+			if tc.err != "" {
+				if _, ok := err.(*SetError); !ok || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("c.Load() == %v; Wanted %T containing %q", err, &SetError{}, tc.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := c.Feature("feat").(*testFeature); got.ThingOne != tc.want.ThingOne || got.Other != tc.want.Other || got.Stuff != tc.want.Stuff {
+				t.Errorf("c.Feature(%q) == %# v; Wanted %# v", "feat", pretty.Formatter(got), pretty.Formatter(tc.want))
+			}
+
+			if got := c.origin("feat.other"); got != tc.origin {
+				t.Errorf("c.origin(%q) == %q; Wanted %q", "feat.other", got, tc.origin)
+			}
+		})
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	type target struct {
+		U uint32
+		D []string
+		B bool
+	}
+
+	tt := reflect.TypeOf(target{})
+
+	for _, tc := range []struct {
+		field string
+		in    string
+		want  interface{}
+	}{
+		{"U", "8080", uint32(8080)},
+		{"D", "a,b", []string{"a", "b"}},
+		{"B", "true", true},
+	} {
+		sf, _ := tt.FieldByName(tc.field)
+		got, err := decodeValue(tc.in, sf.Type)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("decodeValue(%q, %v) == (%#v, %v); Wanted (%#v, nil)", tc.in, sf.Type, got, err, tc.want)
+		}
+	}
+
+	if _, err := decodeValue("-1", reflect.TypeOf(uint32(0))); err == nil {
+		t.Errorf("decodeValue(%q, uint32) == nil error; Wanted error", "-1")
+	}
+}
+
+func TestDecodeValueTags(t *testing.T) {
+	type inner struct {
+		FullName string `cfg:"full-name"`
+	}
+
+	in := map[string]interface{}{"full-name": "Bob"}
+
+	got, err := decodeValue(in, reflect.TypeOf(inner{}))
+	if want := (inner{"Bob"}); err != nil || got != want {
+		t.Errorf("decodeValue(%v, inner) == (%#v, %v); Wanted (%#v, nil)", in, got, err, want)
+	}
+}
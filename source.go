@@ -0,0 +1,408 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"toolman.org/base/log/v2"
+)
+
+// A Source provides configuration settings from somewhere other than the
+// config file found by searching the config path. Sources are added to a
+// Config using the WithSource option.
+//
+// When loaded, the settings from all Sources are merged with those from the
+// config file in order of their Precedence; settings from Sources with a
+// higher Precedence override those from lower. The config file has a
+// Precedence of zero and Sources having equal Precedence are merged in the
+// order they were added (after the config file). All file level settings are
+// overridden by environment variables and flags.
+type Source interface {
+	// Name returns a short description of this Source (e.g. a file name).
+	Name() string
+
+	// Precedence returns this Source's merge precedence.
+	Precedence() int
+
+	// Settings returns this Source's settings as a (possibly nested) map.
+	Settings(context.Context) (map[string]interface{}, error)
+}
+
+// WithPrecedence wraps src so that it has the given Precedence.
+func WithPrecedence(src Source, p int) Source {
+	return &precSource{src, p}
+}
+
+type precSource struct {
+	Source
+	prec int
+}
+
+func (ps *precSource) Precedence() int {
+	return ps.prec
+}
+
+// mergeSources merges the settings from the config file with those from
+// all Sources and returns the normalized result.
+func (c *Config) mergeSources(ctx context.Context, file map[string]interface{}) (map[string]interface{}, error) {
+	srcs := append([]*sourceOpt(nil), c.opts.sources...)
+	sort.SliceStable(srcs, func(i, j int) bool { return srcs[i].src.Precedence() < srcs[j].src.Precedence() })
+
+	raw := make(map[string]interface{})
+	fileDone := false
+
+	for _, so := range srcs {
+		if !fileDone && so.src.Precedence() >= 0 {
+			mergeSettings(raw, file)
+			fileDone = true
+		}
+
+		m, err := so.src.Settings(ctx)
+		if err != nil {
+			switch so.onErr {
+			case RequireConfigFile:
+				return nil, sourceError(so.src.Name(), err)
+			case WarnOnConfigFileErrors:
+				log.Warningf("Error reading config source %s: %v", so.src.Name(), err)
+			}
+			continue
+		}
+
+		log.Infof("Config loaded from source: %s", so.src.Name())
+		c.report.Sources = append(c.report.Sources, so.src.Name())
+
+		mergeSettings(raw, m)
+	}
+
+	if !fileDone {
+		mergeSettings(raw, file)
+	}
+
+	return raw, nil
+}
+
+// mergeSettings recursively merges the settings in src into dst, which must
+// already be normalized. Values from src override those in dst and all keys
+// are lower-cased.
+func mergeSettings(dst, src map[string]interface{}) {
+	for k, v := range src {
+		k = strings.ToLower(k)
+		v = normalize(v)
+
+		sm, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+
+		dm, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dm = make(map[string]interface{})
+			dst[k] = dm
+		}
+
+		mergeSettings(dm, sm)
+	}
+}
+
+// parseConfig parses config data of the given type (e.g. "yaml" or "json").
+func parseConfig(typ string, r io.Reader) (map[string]interface{}, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigType(typ)
+
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		if pe, ok := err.(viper.ConfigParseError); ok {
+			return nil, parseError(typ, data, pe)
+		}
+		return nil, err
+	}
+
+	return v.AllSettings(), nil
+}
+
+var (
+	// e.g. yaml: "yaml: line 3: mapping values are not allowed..."
+	//      toml: "(3, 5): was expecting token..."
+	//       hcl: "At 3:5: illegal char"
+	yamlErrLine = regexp.MustCompile(`\bline (\d+)\b`)
+	tomlErrLine = regexp.MustCompile(`\((\d+), \d+\)`)
+	hclErrLine  = regexp.MustCompile(`\bAt (\d+):\d+`)
+)
+
+// parseError returns a *ConfigParseError for err (a parse error for data of
+// the given type) with as much line information as can be gleaned.
+func parseError(typ string, data []byte, err error) *ConfigParseError {
+	msg := strings.TrimPrefix(err.Error(), "While parsing config: ")
+
+	pe := &ConfigParseError{Err: errors.New(msg)}
+
+	// viper hides the json.SyntaxError we need for its offset so we have to
+	// parse the data again ourselves.
+	if typ == "json" {
+		var v interface{}
+		if se, ok := json.Unmarshal(data, &v).(*json.SyntaxError); ok {
+			pe.Line = 1 + bytes.Count(data[:se.Offset], []byte("\n"))
+			pe.Err = se
+		}
+		return pe
+	}
+
+	for _, re := range []*regexp.Regexp{yamlErrLine, tomlErrLine, hclErrLine} {
+		if m := re.FindStringSubmatch(msg); m != nil {
+			pe.Line, _ = strconv.Atoi(m[1])
+			break
+		}
+	}
+
+	return pe
+}
+
+// basicSource holds the name and precedence for the built-in Sources.
+type basicSource struct {
+	name string
+}
+
+func (bs *basicSource) Name() string    { return bs.name }
+func (bs *basicSource) Precedence() int { return 0 }
+
+//--------------------------------------
+
+// FileSource returns a Source for the named config file. The file's format
+// is determined by its extension.
+func FileSource(path string) Source {
+	return &fileSource{basicSource{path}}
+}
+
+type fileSource struct {
+	basicSource
+}
+
+func (fs *fileSource) Settings(context.Context) (map[string]interface{}, error) {
+	return readConfigFile(fs.name)
+}
+
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := parseConfig(strings.TrimPrefix(filepath.Ext(path), "."), bytes.NewReader(data))
+	if pe, ok := err.(*ConfigParseError); ok {
+		pe.File = path
+	}
+
+	return m, err
+}
+
+//--------------------------------------
+
+// DirSource returns a Source for all config files in the given directory.
+// Files are merged in lexical order and only those having a supported
+// extension (e.g. ".yaml" or ".json") are read; subdirectories are ignored.
+func DirSource(dir string) Source {
+	return &dirSource{basicSource{dir}}
+}
+
+type dirSource struct {
+	basicSource
+}
+
+func (ds *dirSource) Settings(context.Context) (map[string]interface{}, error) {
+	infos, err := ioutil.ReadDir(ds.name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+
+	for _, fi := range infos {
+		ext := strings.TrimPrefix(filepath.Ext(fi.Name()), ".")
+		if fi.IsDir() || !supportedExt(ext) {
+			continue
+		}
+
+		m, err := readConfigFile(filepath.Join(ds.name, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mergeSettings(raw, m)
+	}
+
+	return raw, nil
+}
+
+func supportedExt(ext string) bool {
+	for _, e := range viper.SupportedExts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+//--------------------------------------
+
+// ReaderSource returns a Source for config data of the given type (e.g.
+// "yaml" or "json") read from r. If typ is empty, the format is detected
+// from the data (see DetectFormat). The data is read once, on first use, and
+// retained so that it remains available to Config.Reload.
+func ReaderSource(typ string, r io.Reader) Source {
+	name := "reader"
+	if typ != "" {
+		name += ":" + typ
+	}
+	return &readerSource{basicSource: basicSource{name}, typ: typ, r: r}
+}
+
+type readerSource struct {
+	basicSource
+	typ  string
+	r    io.Reader
+	data []byte
+}
+
+func (rs *readerSource) Settings(context.Context) (map[string]interface{}, error) {
+	if rs.r != nil {
+		data, err := ioutil.ReadAll(rs.r)
+		if err != nil {
+			return nil, err
+		}
+		rs.data, rs.r = data, nil
+	}
+
+	typ := rs.typ
+	if typ == "" {
+		typ = DetectFormat(rs.data)
+	}
+
+	return parseConfig(typ, bytes.NewReader(rs.data))
+}
+
+var (
+	tomlTable = regexp.MustCompile(`^\[\[?\s*[\w.\-"' ]+\]\]?$`)
+	tomlKey   = regexp.MustCompile(`^[\w.\-"']+\s*=`)
+)
+
+// DetectFormat returns the config type ("json", "toml" or "yaml") of the
+// given data. Data is assumed to be JSON if it begins with an opening brace
+// and TOML if its first significant line is a table header or a "key = value"
+// assignment; everything else is considered YAML.
+func DetectFormat(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return "json"
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if tomlTable.MatchString(line) || tomlKey.MatchString(line) {
+			return "toml"
+		}
+
+		break
+	}
+
+	return "yaml"
+}
+
+//--------------------------------------
+
+// MapSource returns a Source with the given name providing the settings in m.
+// Nested settings may be expressed either as nested maps or dot separated
+// keys.
+func MapSource(name string, m map[string]interface{}) Source {
+	return &mapSource{basicSource{name}, m}
+}
+
+type mapSource struct {
+	basicSource
+	m map[string]interface{}
+}
+
+func (ms *mapSource) Settings(context.Context) (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+	for k, v := range ms.m {
+		m := make(map[string]interface{})
+		setKey(m, k, normalize(v))
+		mergeSettings(raw, m)
+	}
+	return raw, nil
+}
+
+//--------------------------------------
+
+// CommandSource returns a Source that runs the named command (with the
+// given arguments) and parses its standard output as config data of the
+// given type (e.g. "yaml" or "json").
+func CommandSource(typ, name string, args ...string) Source {
+	desc := strings.Join(append([]string{name}, args...), " ")
+	return &commandSource{basicSource{"command:" + desc}, typ, name, args}
+}
+
+type commandSource struct {
+	basicSource
+	typ  string
+	cmd  string
+	args []string
+}
+
+func (cs *commandSource) Settings(ctx context.Context) (map[string]interface{}, error) {
+	cmd := exec.CommandContext(ctx, cs.cmd, cs.args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	return parseConfig(cs.typ, bytes.NewReader(out))
+}
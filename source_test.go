@@ -0,0 +1,251 @@
+// Copyright 2019 Timothy E. Peoples
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package basecfg
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+)
+
+func TestSources(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	c := New("sourcetest",
+		FromReader("json", bytes.NewBufferString(`{ "feat": { "thing-one": "file", "other": 1 } }`)),
+		WithSource(MapSource("high", map[string]interface{}{"feat.thing-one": "high"}), RequireConfigFile),
+		WithSource(WithPrecedence(MapSource("low", map[string]interface{}{"feat.other": 2, "feat.stuff": "low"}), -1), RequireConfigFile),
+		WithSource(FileSource("testdata/missing.yml"), WarnOnConfigFileErrors),
+		WithSource(ReaderSource("yaml", bytes.NewBufferString("feat:\n  stuff: reader\n")), RequireConfigFile),
+	)
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &testFeature{ThingOne: "high", Other: 1, Stuff: "reader"}
+
+	if got := c.Feature("feat").(*testFeature); got.ThingOne != want.ThingOne || got.Other != want.Other || got.Stuff != want.Stuff {
+		t.Errorf("c.Feature(%q) == %# v; Wanted %# v", "feat", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	wantSources := []string{"reader:json", "low", "high", "reader:yaml"}
+
+	if got := c.Report().Sources; !reflect.DeepEqual(got, wantSources) {
+		t.Errorf("c.Report().Sources == %q; Wanted %q", got, wantSources)
+	}
+
+	c = New("sourcetest", IgnoreConfigFileErrors, WithSource(FileSource("testdata/missing.yml"), RequireConfigFile))
+
+	if err := c.Load(context.Background()); err == nil {
+		t.Errorf("c.Load() == %v; Wanted %T", err, &SourceError{})
+	}
+}
+
+func TestSourceOrigins(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	RegisterMigration("feat", 0, 1, func(m map[string]interface{}) error {
+		m["thing-one"] = m["thing"]
+		delete(m, "thing")
+		return nil
+	})
+
+	dir, err := ioutil.TempDir("", "basecfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "origin.json")
+	if err := ioutil.WriteFile(file, []byte(`{ "feat": { "thing": "file", "other": 1 } }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New("origintest",
+		WithSource(MapSource("high", map[string]interface{}{"feat.other": 2, "feat.stuff": "high"}), RequireConfigFile),
+	)
+	c.flags.Parse([]string{"--config-file", file})
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]string{"feat.thing-one": originFile, "feat.other": originSource, "feat.stuff": originSource} {
+		if got := c.origin(key); got != want {
+			t.Errorf("c.origin(%q) == %q; Wanted %q", key, got, want)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	for text, want := range map[string]string{
+		`  { "feat": { "other": 1 } }`:     "json",
+		"# comment\n\n[feat]\nother = 1\n": "toml",
+		"feat.other = 1\n":                 "toml",
+		"[[servers]]\nname = 'a'\n":        "toml",
+		"feat:\n  other: 1\n":              "yaml",
+		"---\nfeat:\n  thing-one: a = b\n": "yaml",
+		"- one\n- two\n":                   "yaml",
+		"":                                 "yaml",
+	} {
+		if got := DetectFormat([]byte(text)); got != want {
+			t.Errorf("DetectFormat(%q) == %q; Wanted %q", text, got, want)
+		}
+	}
+}
+
+func TestFromReaders(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	readers := func() []Option {
+		return []Option{
+			FromReader("", bytes.NewBufferString(`{ "feat": { "thing-one": "json" } }`)),
+			FromReader("", bytes.NewBufferString("[feat]\nstuff = \"toml\"\n")),
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want *testFeature
+	}{
+		{"replace", readers(), &testFeature{ThingOne: "json", Other: 12, Stuff: "toml"}},
+		{"layer", append(readers(), LayerReaders), &testFeature{ThingOne: "json", Other: 3, Stuff: "toml"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New("readertest", tc.opts...)
+			c.file = "testdata/readertest.yml"
+
+			if err := c.Load(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := c.Feature("feat").(*testFeature); got.ThingOne != tc.want.ThingOne || got.Other != tc.want.Other || got.Stuff != tc.want.Stuff {
+				t.Errorf("c.Feature(%q) == %# v; Wanted %# v", "feat", pretty.Formatter(got), pretty.Formatter(tc.want))
+			}
+		})
+	}
+}
+
+func TestDefaultConfig(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("feat", func() Feature { return mkTestFeature() })
+
+	c := New("defconfigtest",
+		DefaultConfig("", []byte("feat:\n  thing-one: bundled\n  other: 5\n")),
+		FromReader("json", bytes.NewBufferString(`{ "feat": { "other": 1 } }`)),
+	)
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &testFeature{ThingOne: "bundled", Other: 1, Stuff: "bar"}
+
+	if got := c.Feature("feat").(*testFeature); got.ThingOne != want.ThingOne || got.Other != want.Other || got.Stuff != want.Stuff {
+		t.Errorf("c.Feature(%q) == %# v; Wanted %# v", "feat", pretty.Formatter(got), pretty.Formatter(want))
+	}
+
+	for key, want := range map[string]string{"feat.thing-one": originBundle, "feat.other": originSource, "feat.stuff": originDefault} {
+		if got := c.origin(key); got != want {
+			t.Errorf("c.origin(%q) == %q; Wanted %q", key, got, want)
+		}
+	}
+
+	wantSources := []string{"default-config", "reader:json"}
+
+	if got := c.Report().Sources; !reflect.DeepEqual(got, wantSources) {
+		t.Errorf("c.Report().Sources == %q; Wanted %q", got, wantSources)
+	}
+}
+
+func TestDefaultConfigOneOf(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	RegisterOneOf("otf", "feata", func() Feature { return new(oneofFeatureA) })
+	RegisterOneOf("otf", "featb", func() Feature { return new(oneofFeatureB) })
+
+	c := New("defconfigtest",
+		DefaultConfig("yaml", []byte("feata:\n  option: x\n")),
+		FromReader("json", bytes.NewBufferString(`{ "featb": { "option": "y" } }`)),
+	)
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &oneofFeatureB{Option: "y"}
+
+	if got := c.OneOf("otf"); !reflect.DeepEqual(got, want) {
+		t.Errorf("c.OneOf(%q) == %#v; Wanted %#v", "otf", got, want)
+	}
+}
+
+func TestDefaultConfigKeys(t *testing.T) {
+	reset := useTestRegistry()
+	defer reset()
+
+	Register("net", func() Feature { return new(aliasFeature) })
+
+	c := New("defconfigtest", DefaultConfig("yaml", []byte("net:\n  addr: \":80\"\n")), IgnoreConfigFileErrors)
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := c.Feature("net").(*aliasFeature).Addr, ":80"; got != want {
+		t.Errorf("Addr == %q; Wanted %q", got, want)
+	}
+
+	uses := []*AliasUse{{"net.addr", "net.listen-addr", originBundle}}
+	if got := c.Report().Aliases; !reflect.DeepEqual(got, uses) {
+		t.Errorf("c.Report().Aliases == %# v; Wanted %# v", pretty.Formatter(got), pretty.Formatter(uses))
+	}
+
+	c = New("defconfigtest", DefaultConfig("yaml", []byte("net:\n  nmae: x\n")), IgnoreConfigFileErrors, StrictKeys)
+
+	want := unknownKeysError([]*UnknownKey{{"net.nmae", originBundle, []string{"net.name"}}})
+
+	if err := c.Load(context.Background()); !reflect.DeepEqual(err, want) {
+		t.Errorf("c.Load() == (%v); Wanted (%v)", err, want)
+	}
+}